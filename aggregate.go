@@ -0,0 +1,120 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// AggregateError holds many errors, for example the failures of several backends called at once. Each
+// one keeps its own chain, so it can be a wrapped error with its own fields and location.
+type AggregateError struct {
+	errs []error
+}
+
+// Aggregate returns a new AggregateError with the provided errors, skipping nil ones. It returns nil
+// when there is no error left, which is not a nil error once converted to the error interface, so use
+// Combine to return it from a function whose result is an error.
+func Aggregate(errs ...error) *AggregateError {
+	agg := &AggregateError{}
+	for _, err := range errs {
+		if err != nil {
+			agg.errs = append(agg.errs, err)
+		}
+	}
+	if len(agg.errs) == 0 {
+		return nil
+	}
+	return agg
+}
+
+// Combine returns a new AggregateError with the provided errors, skipping nil ones, or a nil error when
+// there is no error left.
+func Combine(errs ...error) error {
+	if agg := Aggregate(errs...); agg != nil {
+		return agg
+	}
+	return nil
+}
+
+// AggregateFromJoin takes a value returned by errors.Join, or any error with an Unwrap() []error
+// method, and returns an AggregateError with the same errors. Any other error is aggregated alone. Like
+// Aggregate, it returns a nil *AggregateError when there is no error.
+func AggregateFromJoin(err error) *AggregateError {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return Aggregate(joined.Unwrap()...)
+	}
+	return Aggregate(err)
+}
+
+// Join returns the errors of the aggregate joined with errors.Join.
+func (a *AggregateError) Join() error {
+	return errors.Join(a.errs...)
+}
+
+// Errors returns the aggregated errors.
+func (a *AggregateError) Errors() []error {
+	return append([]error(nil), a.errs...)
+}
+
+// Unwrap returns the aggregated errors, so errors.Is, errors.As, Walk and the Contains functions can
+// look into each of them.
+func (a *AggregateError) Unwrap() []error {
+	return a.errs
+}
+
+// Error returns the number of errors followed by the message of each one of them, rendered with the
+// renderer set by SetRenderer.
+func (a *AggregateError) Error() string {
+	return currentRenderer().Render(a)
+}
+
+// GetAllFields returns a map of the fields of every aggregated error, redacted with the redaction
+// policy and merged with the merge policy, with the code of the aggregate added as the CodeField field
+// the same way as WrappedErrorImpl.GetAllFields.
+func (a *AggregateError) GetAllFields() map[string]interface{} {
+	return redactFields(a.GetAllFieldsUnredacted())
+}
+
+// GetAllFieldsUnredacted returns the same fields as GetAllFields with their original values.
+func (a *AggregateError) GetAllFieldsUnredacted() map[string]interface{} {
+	return withCodeField(mergeFieldSources(fieldSources(a), currentMergePolicy()), a)
+}
+
+// GetFieldsWithProvenance returns every value set for each field in the aggregated errors, with the
+// layer and location where it was set.
+func (a *AggregateError) GetFieldsWithProvenance() map[string][]FieldSource {
+	return redactFieldSources(fieldSources(a))
+}
+
+// Format implements fmt.Formatter. %s and %v print the same message as Error(), %q prints it quoted
+// and %+v prints every aggregated error in its verbose form. Other verbs print the message the way fmt
+// reports a wrong verb.
+func (a *AggregateError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			writeVerboseAggregate(s, a)
+			return
+		}
+		io.WriteString(s, a.Error())
+	case 's':
+		io.WriteString(s, a.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", a.Error())
+	default:
+		writeBadVerb(s, verb, a)
+	}
+}
+
+// MarshalJSON returns every aggregated error in its JSON form and the merged fields of all of them.
+func (a *AggregateError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Errors []json.RawMessage          `json:"errors"`
+		Fields map[string]json.RawMessage `json:"fields"`
+	}{
+		Errors: jsonErrors(a.errs),
+		Fields: jsonFields(a.GetAllFields()),
+	})
+}
@@ -0,0 +1,106 @@
+package errors
+
+import (
+	"encoding/json"
+	goerr "errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregate(t *testing.T) {
+	backendA := NewWithMsgAndFields("backend a failed", map[string]interface{}{"backend": "a"})
+	backendB := NewWithMsgAndFields("backend b failed", map[string]interface{}{"backend": "b", "status": 503})
+	errPlain := goerr.New("backend c failed")
+
+	t.Run("should return nil when there are no errors", func(t *testing.T) {
+		assert.Nil(t, Aggregate())
+		assert.Nil(t, Aggregate(nil, nil))
+	})
+
+	t.Run("should combine the errors into a nil error when there are none", func(t *testing.T) {
+		combine := func(errs ...error) error {
+			return Combine(errs...)
+		}
+		assert.True(t, combine(nil, nil) == nil)
+		assert.Equal(t, Aggregate(backendA, errPlain), combine(backendA, nil, errPlain))
+	})
+
+	t.Run("should print every error", func(t *testing.T) {
+		agg := Aggregate(backendA, nil, errPlain)
+		assert.Equal(t, "Errors: 2. <br> [1] "+backendA.Error()+" <br> [2] backend c failed", agg.Error())
+		assert.Len(t, agg.Errors(), 2)
+	})
+
+	t.Run("should merge the fields of every error", func(t *testing.T) {
+		agg := Aggregate(backendA, backendB, errPlain)
+		assert.Equal(t, map[string]interface{}{"backend": "a", "status": 503}, agg.GetAllFields())
+	})
+
+	t.Run("should merge the fields of an aggregate wrapped in a chain", func(t *testing.T) {
+		err := WithErrorAndFields(Aggregate(backendB), goerr.New("fan out failed"), map[string]interface{}{"request": 1})
+		assert.Equal(t, map[string]interface{}{"backend": "b", "status": 503, "request": 1}, err.GetAllFields())
+	})
+
+	t.Run("should find errors inside an aggregate with the Contains functions", func(t *testing.T) {
+		err := WithErrorAndFields(Aggregate(backendA, errPlain), goerr.New("fan out failed"), fields)
+
+		found, foundFields, ok := ContainsError(errPlain, err)
+		assert.True(t, ok)
+		assert.Equal(t, errPlain, found)
+		assert.Equal(t, fields, foundFields)
+
+		found, foundFields, ok = ContainsErrorPrefix("backend a", err)
+		assert.True(t, ok)
+		assert.Equal(t, "backend a failed", found.Error())
+		assert.Equal(t, map[string]interface{}{"backend": "a"}, foundFields)
+
+		assert.True(t, goerr.Is(err, errPlain))
+	})
+
+	t.Run("should convert to and from errors.Join", func(t *testing.T) {
+		joined := goerr.Join(backendA, errPlain)
+		agg := AggregateFromJoin(joined)
+		assert.Equal(t, []error{backendA, errPlain}, agg.Errors())
+		assert.Equal(t, joined, agg.Join())
+		assert.Equal(t, []error{errPlain}, AggregateFromJoin(errPlain).Errors())
+		assert.Nil(t, AggregateFromJoin(nil))
+	})
+
+	t.Run("should print every error with %+v", func(t *testing.T) {
+		err := WithError(Aggregate(backendA, errPlain), goerr.New("fan out failed"))
+		verbose := fmt.Sprintf("%+v", err)
+		assert.True(t, strings.HasPrefix(verbose, "Message: fan out failed\n"), verbose)
+		assert.Contains(t, verbose, "Errors: 2\n[1]\n\tMessage: backend a failed\n\t\tLocation: github.com/hantonelli/errors/aggregate_test.go:14\n")
+		assert.Contains(t, verbose, "\t\tFields: map[backend:a]\n\tStacktrace:\n")
+		assert.Contains(t, verbose, "[2]\n\tMessage: backend c failed\n")
+	})
+
+	t.Run("should marshal every error", func(t *testing.T) {
+		err := WithError(Aggregate(backendA, errPlain), goerr.New("fan out failed"))
+		b, jsonErr := json.Marshal(err)
+		assert.NoError(t, jsonErr)
+
+		var chain struct {
+			Layers []struct {
+				Message string `json:"message"`
+				Errors  []struct {
+					Layers []struct {
+						Message string `json:"message"`
+					} `json:"layers"`
+					Fields map[string]interface{} `json:"fields"`
+				} `json:"errors"`
+			} `json:"layers"`
+			Fields map[string]interface{} `json:"fields"`
+		}
+		assert.NoError(t, json.Unmarshal(b, &chain))
+		assert.Len(t, chain.Layers, 2)
+		assert.Len(t, chain.Layers[1].Errors, 2)
+		assert.Equal(t, "backend a failed", chain.Layers[1].Errors[0].Layers[0].Message)
+		assert.Equal(t, "a", chain.Layers[1].Errors[0].Fields["backend"])
+		assert.Equal(t, "backend c failed", chain.Layers[1].Errors[1].Layers[0].Message)
+		assert.Equal(t, "a", chain.Fields["backend"])
+	})
+}
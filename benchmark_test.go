@@ -0,0 +1,34 @@
+package errors
+
+import (
+	goerr "errors"
+	"testing"
+)
+
+var benchmarkSink error
+
+func BenchmarkNewWithMsg(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchmarkSink = NewWithMsg("benchmark")
+	}
+}
+
+func BenchmarkWithError(b *testing.B) {
+	previous := NewWithMsg("previous")
+	actual := goerr.New("actual")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchmarkSink = WithError(previous, actual)
+	}
+}
+
+func BenchmarkError(b *testing.B) {
+	err := getThirdWrap()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = err.Error()
+	}
+}
@@ -0,0 +1,130 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+const badKey = "!BADKEY"
+
+// Builder builds a WrappedErrorImpl step by step, for example
+// errors.Msg("user not found").With("user_id", id).Code("not_found").Wrap(err).Err().
+type Builder struct {
+	actual     error
+	previous   error
+	fields     map[string]interface{}
+	code       Code
+	messageKey string
+	ctx        context.Context
+}
+
+// Msg returns a new Builder for an error with the provided message.
+func Msg(message string) *Builder {
+	return &Builder{actual: errors.New(message)}
+}
+
+// From returns a new Builder for the provided actual error.
+func From(actual error) *Builder {
+	return &Builder{actual: actual}
+}
+
+// With adds fields from alternating keys and values, the same way as slog: a string followed by a value
+// is a field, a slog.Attr is a field, and anything else is added under the "!BADKEY" key.
+func (b *Builder) With(args ...interface{}) *Builder {
+	for len(args) > 0 {
+		switch x := args[0].(type) {
+		case string:
+			if len(args) == 1 {
+				b.set(badKey, x)
+				args = args[1:]
+				continue
+			}
+			b.set(x, args[1])
+			args = args[2:]
+		case slog.Attr:
+			b.set(x.Key, x.Value.Resolve().Any())
+			args = args[1:]
+		default:
+			b.set(badKey, x)
+			args = args[1:]
+		}
+	}
+	return b
+}
+
+// Fields adds the provided fields.
+func (b *Builder) Fields(fields map[string]interface{}) *Builder {
+	for k, v := range fields {
+		b.set(k, v)
+	}
+	return b
+}
+
+// Code sets the code of the layer.
+func (b *Builder) Code(code Code) *Builder {
+	b.code = code
+	return b
+}
+
+// MessageKey sets the message key of the layer, used to translate its message.
+func (b *Builder) MessageKey(key string) *Builder {
+	b.messageKey = key
+	return b
+}
+
+// Ctx sets the context whose fields, found by the registered context extractors, are added to the
+// error. Fields added explicitly win over the ones found in the context.
+func (b *Builder) Ctx(ctx context.Context) *Builder {
+	b.ctx = ctx
+	return b
+}
+
+// Wrap sets the previous error in the chain.
+func (b *Builder) Wrap(previous error) *Builder {
+	b.previous = previous
+	return b
+}
+
+// Err returns a new WrappedErrorImpl located where Err is called. It returns nil when the actual
+// error is nil. The builder can be reused, it does not share its fields with the errors it returns.
+func (b *Builder) Err() *WrappedErrorImpl {
+	return b.build()
+}
+
+func (b *Builder) set(key string, value interface{}) {
+	if b.fields == nil {
+		b.fields = map[string]interface{}{}
+	}
+	b.fields[key] = value
+}
+
+// build returns the error located two frames above it, that is the caller of the function calling build.
+func (b *Builder) build() *WrappedErrorImpl {
+	if b.actual == nil {
+		return nil
+	}
+	e := &WrappedErrorImpl{
+		actual:     b.actual,
+		previous:   b.previous,
+		fields:     copyFields(b.fields),
+		code:       b.code,
+		messageKey: b.messageKey,
+	}
+	if b.ctx != nil {
+		for k, v := range contextFields(b.ctx) {
+			if _, exists := e.fields[k]; !exists {
+				e.fields[k] = v
+			}
+		}
+	}
+	if b.previous == nil {
+		e.stack, e.stackTruncated = captureStack(2)
+	}
+	if len(e.stack) > 0 {
+		e.pc = e.stack[0]
+	} else {
+		e.pc = callerPC(2)
+	}
+	return e
+}
@@ -0,0 +1,56 @@
+package errors
+
+import (
+	goerr "errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder(t *testing.T) {
+	errPrevious := goerr.New("previous")
+
+	t.Run("should build an error located where Err is called", func(t *testing.T) {
+		err := Msg("user not found").
+			With("user_id", 42).
+			Code("not_found").
+			Wrap(errPrevious).
+			Err()
+		assert.Equal(t, "Message: user not found. Code: not_found. Location: github.com/hantonelli/errors/builder_test.go:19. Fields: map[user_id:42]. <br> Message: previous.", err.Error())
+		assert.Equal(t, errPrevious, err.GetPrevious())
+		assert.Empty(t, err.GetStackFrames())
+	})
+
+	t.Run("should capture the stack when there is no previous error", func(t *testing.T) {
+		err := From(errPrevious).Err()
+		assert.Equal(t, errPrevious, err.GetActual())
+		assert.Equal(t, "github.com/hantonelli/errors/builder_test.go:26", err.GetStackFrames()[0].String())
+	})
+
+	t.Run("should add slog style fields", func(t *testing.T) {
+		err := Msg("actual").
+			With("key", "value", slog.Int("count", 2), "odd").
+			With(3).
+			Fields(map[string]interface{}{"map": true}).
+			Err()
+		assert.Equal(t, map[string]interface{}{
+			"key":   "value",
+			"count": int64(2),
+			badKey:  3,
+			"map":   true,
+		}, err.GetFields())
+	})
+
+	t.Run("should return nil when the actual error is nil", func(t *testing.T) {
+		assert.Nil(t, From(nil).With("key", "value").Err())
+	})
+
+	t.Run("should not share the fields between errors", func(t *testing.T) {
+		builder := Msg("actual").With("key", "value")
+		first := builder.Err()
+		second := builder.With("key", "other").Err()
+		assert.Equal(t, "value", first.GetFields()["key"])
+		assert.Equal(t, "other", second.GetFields()["key"])
+	})
+}
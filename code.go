@@ -0,0 +1,132 @@
+package errors
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// CodeField is the field where GetAllFields reports the code of the chain, unless the chain has its own
+// field with that name.
+const CodeField = "code"
+
+// Code is a machine readable error code, such as "not_found".
+type Code string
+
+// CodeResolver returns the code of a whole chain from the codes of its layers.
+type CodeResolver func(err error) Code
+
+var (
+	codeResolver atomic.Value
+
+	httpStatusesMu    sync.RWMutex
+	httpStatuses      = map[Code]int{}
+	defaultHTTPStatus = 500
+)
+
+func init() {
+	codeResolver.Store(CodeResolver(OutermostCode))
+}
+
+// GetCode returns the code of this layer, which is empty when it has none.
+func (e WrappedErrorImpl) GetCode() Code {
+	return e.code
+}
+
+// WithCode returns a copy of the error with the provided code on its layer.
+func (e *WrappedErrorImpl) WithCode(code Code) *WrappedErrorImpl {
+	if e == nil {
+		return nil
+	}
+	withCode := *e
+	withCode.code = code
+	return &withCode
+}
+
+// CodeOf returns the code of the chain, resolved with the resolver set by SetCodeResolver. By default
+// the outermost non-empty code wins.
+func CodeOf(err error) Code {
+	if err == nil {
+		return ""
+	}
+	return codeResolver.Load().(CodeResolver)(err)
+}
+
+// SetCodeResolver sets how the code of a chain is resolved from the codes of its layers.
+func SetCodeResolver(resolver CodeResolver) {
+	if resolver == nil {
+		resolver = OutermostCode
+	}
+	codeResolver.Store(resolver)
+}
+
+// OutermostCode returns the first non-empty code found walking the chain from the outermost layer.
+func OutermostCode(err error) Code {
+	var code Code
+	Walk(err, func(l Layer) bool {
+		code = errorCode(l.Err)
+		return code == ""
+	})
+	return code
+}
+
+// InnermostCode returns the last non-empty code found walking the chain from the outermost layer.
+func InnermostCode(err error) Code {
+	var code Code
+	Walk(err, func(l Layer) bool {
+		if c := errorCode(l.Err); c != "" {
+			code = c
+		}
+		return true
+	})
+	return code
+}
+
+// errorCode returns the code of the layer of err, when err has a GetCode method.
+func errorCode(err error) Code {
+	if coded, ok := err.(interface{ GetCode() Code }); ok {
+		return coded.GetCode()
+	}
+	return ""
+}
+
+// withCodeField adds the code of err to fields as the CodeField field, without replacing a field of the
+// chain with the same name.
+func withCodeField(fields map[string]interface{}, err error) map[string]interface{} {
+	if _, exists := fields[CodeField]; exists {
+		return fields
+	}
+	if code := CodeOf(err); code != "" {
+		fields[CodeField] = code
+	}
+	return fields
+}
+
+// RegisterHTTPStatus maps a code to the HTTP status returned by HTTPStatus.
+func RegisterHTTPStatus(code Code, status int) {
+	httpStatusesMu.Lock()
+	defer httpStatusesMu.Unlock()
+	httpStatuses[code] = status
+}
+
+// SetDefaultHTTPStatus sets the HTTP status returned by HTTPStatus for chains whose code has not been
+// registered. It is 500 unless it is changed.
+func SetDefaultHTTPStatus(status int) {
+	httpStatusesMu.Lock()
+	defer httpStatusesMu.Unlock()
+	defaultHTTPStatus = status
+}
+
+// HTTPStatus returns the HTTP status registered for the code of the chain, or the default status when
+// there is none. It returns 200 for a nil error.
+func HTTPStatus(err error) int {
+	if err == nil {
+		return 200
+	}
+	code := CodeOf(err)
+	httpStatusesMu.RLock()
+	defer httpStatusesMu.RUnlock()
+	if status, ok := httpStatuses[code]; ok && code != "" {
+		return status
+	}
+	return defaultHTTPStatus
+}
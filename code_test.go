@@ -0,0 +1,118 @@
+package errors
+
+import (
+	"encoding/json"
+	goerr "errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCode(t *testing.T) {
+	const (
+		codeNotFound Code = "not_found"
+		codeInvalid  Code = "invalid"
+	)
+
+	t.Run("should keep the code on a copy of the layer", func(t *testing.T) {
+		err := NewWithMsg("user not found")
+		withCode := err.WithCode(codeNotFound)
+		assert.Equal(t, Code(""), err.GetCode())
+		assert.Equal(t, codeNotFound, withCode.GetCode())
+		assert.Nil(t, (*WrappedErrorImpl)(nil).WithCode(codeNotFound))
+	})
+
+	t.Run("should resolve the outermost code by default", func(t *testing.T) {
+		inner := NewWithMsg("user not found").WithCode(codeNotFound)
+		middle := WithError(inner, goerr.New("load user"))
+		outer := WithError(middle, goerr.New("bad request")).WithCode(codeInvalid)
+
+		assert.Equal(t, codeNotFound, CodeOf(middle))
+		assert.Equal(t, codeInvalid, CodeOf(outer))
+		assert.Equal(t, codeNotFound, CodeOf(fmt.Errorf("handler: %w", middle)))
+		assert.Equal(t, Code(""), CodeOf(goerr.New("plain")))
+		assert.Equal(t, Code(""), CodeOf(nil))
+	})
+
+	t.Run("should resolve the code with the resolver set", func(t *testing.T) {
+		SetCodeResolver(InnermostCode)
+		defer SetCodeResolver(nil)
+
+		inner := NewWithMsg("user not found").WithCode(codeNotFound)
+		outer := WithError(inner, goerr.New("bad request")).WithCode(codeInvalid)
+		assert.Equal(t, codeNotFound, CodeOf(outer))
+	})
+
+	t.Run("should not replace a field named as the code field", func(t *testing.T) {
+		err := NewWithMsgAndFields("x", map[string]interface{}{CodeField: 42}).WithCode(codeNotFound)
+		assert.Equal(t, map[string]interface{}{CodeField: 42}, err.GetAllFields())
+		assert.Equal(t, map[string]interface{}{CodeField: 42}, Aggregate(err).GetAllFields())
+		assert.Equal(t, codeNotFound, CodeOf(err))
+	})
+
+	t.Run("should show the code in Error, the fields and the serialized forms", func(t *testing.T) {
+		err := NewWithMsgAndFields("user not found", fields).WithCode(codeNotFound)
+		assert.Contains(t, err.Error(), "Message: user not found. Code: not_found. Location: ")
+		assert.Equal(t, codeNotFound, err.GetAllFields()[CodeField])
+		assert.NotContains(t, err.GetFields(), CodeField)
+		assert.Equal(t, codeNotFound, Aggregate(err).GetAllFields()[CodeField])
+		assert.Contains(t, fmt.Sprintf("%+v", err), "Message: user not found\n\tCode: not_found\n")
+
+		b, jsonErr := json.Marshal(err)
+		assert.NoError(t, jsonErr)
+		assert.Contains(t, string(b), `{"code":"not_found","layers":[{"message":"user not found","code":"not_found",`)
+	})
+}
+
+func TestHTTPStatus(t *testing.T) {
+	RegisterHTTPStatus("http_test_not_found", 404)
+	RegisterHTTPStatus("http_test_invalid", 400)
+
+	t.Run("should map the code of the chain", func(t *testing.T) {
+		err := WithError(NewWithMsg("user not found").WithCode("http_test_not_found"), goerr.New("load user"))
+		assert.Equal(t, 404, HTTPStatus(err))
+		assert.Equal(t, 400, HTTPStatus(NewWithMsg("bad input").WithCode("http_test_invalid")))
+	})
+
+	t.Run("should return the default status", func(t *testing.T) {
+		assert.Equal(t, 500, HTTPStatus(goerr.New("plain")))
+		assert.Equal(t, 500, HTTPStatus(NewWithMsg("unknown").WithCode("http_test_unknown")))
+
+		SetDefaultHTTPStatus(503)
+		defer SetDefaultHTTPStatus(500)
+		assert.Equal(t, 503, HTTPStatus(goerr.New("plain")))
+	})
+
+	t.Run("should return 200 for a nil error", func(t *testing.T) {
+		assert.Equal(t, 200, HTTPStatus(nil))
+	})
+}
+
+// uncodedWrappedError implements WrappedError without a GetCode method.
+type uncodedWrappedError struct {
+	actual, previous error
+}
+
+func (e uncodedWrappedError) Error() string                        { return e.actual.Error() }
+func (e uncodedWrappedError) IsWrappedError() bool                 { return true }
+func (e uncodedWrappedError) GetPrevious() error                   { return e.previous }
+func (e uncodedWrappedError) GetActual() error                     { return e.actual }
+func (e uncodedWrappedError) GetFields() map[string]interface{}    { return nil }
+func (e uncodedWrappedError) GetAllFields() map[string]interface{} { return nil }
+func (e uncodedWrappedError) GetStacktrace() string                { return "" }
+func (e uncodedWrappedError) GetStackFrames() []Frame              { return nil }
+
+func TestCodeWithoutGetCode(t *testing.T) {
+	var _ WrappedError = uncodedWrappedError{}
+
+	t.Run("should resolve the code through layers without codes", func(t *testing.T) {
+		inner := NewWithMsg("user not found").WithCode("not_found")
+		err := WithError(uncodedWrappedError{actual: goerr.New("middle"), previous: inner}, goerr.New("outer"))
+		assert.Equal(t, Code("not_found"), CodeOf(err))
+		assert.Contains(t, fmt.Sprintf("%+v", err), "Message: middle\n")
+		assert.Contains(t, CompactRenderer{}.Render(err), ": middle: user not found")
+		_, jsonErr := json.Marshal(err)
+		assert.NoError(t, jsonErr)
+	})
+}
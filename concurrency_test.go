@@ -0,0 +1,72 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	goerr "errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldsImmutability(t *testing.T) {
+
+	t.Run("should copy the fields provided", func(t *testing.T) {
+		provided := map[string]interface{}{"key": "value"}
+		err := NewWithMsgAndFields("actual", provided)
+		provided["key"] = "changed"
+		provided["other"] = 1
+		assert.Equal(t, map[string]interface{}{"key": "value"}, err.GetFields())
+	})
+
+	t.Run("should not expose the fields of the error", func(t *testing.T) {
+		err := NewWithMsgAndFields("actual", map[string]interface{}{"key": "value"})
+		err.GetFieldsUnredacted()["key"] = "changed"
+		err.GetFields()["key"] = "changed"
+		err.GetAllFieldsUnredacted()["key"] = "changed"
+		assert.Equal(t, "value", err.GetFields()["key"])
+	})
+
+	t.Run("should annotate a copy of the error", func(t *testing.T) {
+		err := NewWithMsgAndFields("actual", map[string]interface{}{"key": "value"})
+		annotated := err.WithField("request", 1).WithFields(map[string]interface{}{"key": "other"})
+		assert.Equal(t, map[string]interface{}{"key": "value"}, err.GetFields())
+		assert.Equal(t, map[string]interface{}{"key": "other", "request": 1}, annotated.GetFields())
+		assert.Equal(t, err.location(), annotated.location())
+		assert.Nil(t, (*WrappedErrorImpl)(nil).WithField("key", "value"))
+	})
+}
+
+// TestConcurrentAccess is meant to be run with the race detector.
+func TestConcurrentAccess(t *testing.T) {
+	sentinel := WithErrorAndFields(getThirdWrap(), goerr.New("cached sentinel"), map[string]interface{}{"shared": true})
+	shared := WithError(Aggregate(sentinel, NewWithMsgAndFields("other", fields)), goerr.New("fan out")).WithCode("concurrent")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var logs bytes.Buffer
+			logger := slog.New(NewSlogHandler(slog.NewJSONHandler(&logs, nil)))
+			logger.Error("failed", "err", shared)
+
+			_ = shared.Error()
+			_ = fmt.Sprintf("%+v", shared)
+			_, jsonErr := json.Marshal(shared)
+			assert.NoError(t, jsonErr)
+			_ = shared.GetAllFields()
+			_ = shared.GetFieldsWithProvenance()
+			_, _, _ = ContainsErrorPrefix("cached", shared)
+			_ = CodeOf(shared)
+
+			annotated := sentinel.WithField("goroutine", i)
+			assert.Equal(t, i, annotated.GetFields()["goroutine"])
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, map[string]interface{}{"shared": true}, sentinel.GetFields())
+}
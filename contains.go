@@ -1,6 +1,6 @@
 package errors
 
-// GenericError is an interface of the error to use as an example of how to write a Contains function.
+// GenericError is an interface of the error to use as an example of how to look for an error type with Contains.
 type GenericError interface {
 	error
 	IsGenericError() bool
@@ -17,29 +17,22 @@ func (g genericError) IsGenericError() bool {
 	return true
 }
 
+// Contains takes an error chain and returns the first error in it that is of type T, or implements T
+// when T is an interface, with the fields of the layer where it was found. It looks at the actual and
+// previous errors of every wrapped layer and follows Unwrap on other errors.
+func Contains[T any](err error) (T, map[string]interface{}, bool) {
+	var zero T
+	found, fields, ok := findInChain(err, func(e error) bool {
+		_, isExpectedType := e.(T)
+		return isExpectedType
+	})
+	if !ok {
+		return zero, map[string]interface{}{}, false
+	}
+	return found.(T), fields, true
+}
+
 // ContainsGenericError takes an error and returns a concrete error if it is present in the error chain.
 func ContainsGenericError(err error) (GenericError, map[string]interface{}, bool) {
-	ce, isExpectedType := err.(GenericError)
-	if isExpectedType {
-		return ce, map[string]interface{}{}, true
-	}
-	we, isWrappedError := err.(WrappedError)
-	if !isWrappedError {
-		return nil, map[string]interface{}{}, false
-	}
-	ge1, isActualExpectedType := we.GetActual().(genericError)
-	if isActualExpectedType {
-		return ge1, we.GetFields(), true
-	}
-	if we.GetPrevious() != nil {
-		ge2, isPreviousExpectedType := we.GetPrevious().(genericError)
-		if isPreviousExpectedType {
-			return ge2, we.GetFields(), true
-		}
-		prev, isPreviousWrappedError := we.GetPrevious().(WrappedError)
-		if isPreviousWrappedError {
-			return ContainsGenericError(prev)
-		}
-	}
-	return nil, map[string]interface{}{}, false
+	return Contains[GenericError](err)
 }
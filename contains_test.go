@@ -2,6 +2,7 @@ package errors
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
 	"testing"
 
@@ -106,3 +107,100 @@ func TestContainsGenericError(t *testing.T) {
 		}
 	})
 }
+
+func TestContainsGenericErrorStandardChains(t *testing.T) {
+	ge := genericError{}
+	firstFields := map[string]interface{}{
+		"first-wrap-string": "test-string",
+	}
+
+	t.Run("should return GE wrapped with fmt.Errorf under a wrapped error", func(t *testing.T) {
+		firstWrap := WithErrorAndFields(fmt.Errorf("context: %w", ge), errors.New("first"), firstFields)
+
+		extractedGE, fields, ok := ContainsGenericError(firstWrap)
+		assert.True(t, ok)
+		assert.Equal(t, ge, extractedGE)
+		assert.Equal(t, firstFields, fields)
+	})
+
+	t.Run("should return GE from a wrapped error under errors.Join", func(t *testing.T) {
+		firstWrap := NewWithErrorAndFields(ge, firstFields)
+		err := errors.Join(errors.New("other"), firstWrap)
+
+		extractedGE, fields, ok := ContainsGenericError(err)
+		assert.True(t, ok)
+		assert.Equal(t, ge, extractedGE)
+		assert.Equal(t, firstFields, fields)
+	})
+}
+
+type codeError struct {
+	code int
+}
+
+func (c *codeError) Error() string {
+	return fmt.Sprintf("code %d", c.code)
+}
+
+func TestContains(t *testing.T) {
+	firstFields := map[string]interface{}{
+		"first-wrap-string": "test-string",
+	}
+	secondFields := map[string]interface{}{
+		"second-wrap-string": "test-string",
+	}
+
+	t.Run("should return false when the type is not in the chain", func(t *testing.T) {
+		err := WithError(errors.New("previous"), errors.New("actual"))
+		found, fields, ok := Contains[*codeError](err)
+		assert.False(t, ok)
+		assert.Nil(t, found)
+		assert.Equal(t, map[string]interface{}{}, fields)
+	})
+
+	t.Run("should return false when the error is nil", func(t *testing.T) {
+		_, _, ok := Contains[*codeError](nil)
+		assert.False(t, ok)
+	})
+
+	t.Run("should match by concrete type in the actual branch", func(t *testing.T) {
+		ce := &codeError{code: 404}
+		firstWrap := WithErrorAndFields(errors.New("previous"), ce, firstFields)
+		secondWrap := WithErrorAndFields(firstWrap, errors.New("second"), secondFields)
+
+		found, fields, ok := Contains[*codeError](secondWrap)
+		assert.True(t, ok)
+		assert.Equal(t, ce, found)
+		assert.Equal(t, firstFields, fields)
+	})
+
+	t.Run("should match by concrete type in the previous branch", func(t *testing.T) {
+		ce := &codeError{code: 500}
+		firstWrap := WithErrorAndFields(ce, errors.New("first"), firstFields)
+		secondWrap := WithErrorAndFields(firstWrap, errors.New("second"), secondFields)
+
+		found, fields, ok := Contains[*codeError](secondWrap)
+		assert.True(t, ok)
+		assert.Equal(t, 500, found.code)
+		assert.Equal(t, firstFields, fields)
+	})
+
+	t.Run("should match by interface", func(t *testing.T) {
+		secondWrap := WithErrorAndFields(NewWithErrorAndFields(genericError{}, firstFields), errors.New("second"), secondFields)
+
+		found, fields, ok := Contains[GenericError](secondWrap)
+		assert.True(t, ok)
+		assert.True(t, found.IsGenericError())
+		assert.Equal(t, firstFields, fields)
+	})
+
+	t.Run("should return the wrapped error itself", func(t *testing.T) {
+		firstWrap := NewWithErrorAndFields(errors.New("first"), firstFields)
+		err := fmt.Errorf("outer: %w", firstWrap)
+
+		found, fields, ok := Contains[*WrappedErrorImpl](err)
+		assert.True(t, ok)
+		assert.Equal(t, firstWrap, found)
+		assert.Equal(t, firstFields, fields)
+	})
+}
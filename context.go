@@ -0,0 +1,113 @@
+package errors
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+	"sync"
+)
+
+const (
+	// TraceIDField is the field where TraceparentExtractor adds the trace id.
+	TraceIDField = "trace_id"
+	// SpanIDField is the field where TraceparentExtractor adds the span id.
+	SpanIDField = "span_id"
+)
+
+// ContextExtractor returns the fields found in a context, such as the request id or the tenant.
+type ContextExtractor func(ctx context.Context) map[string]interface{}
+
+type traceparentKey struct{}
+
+var (
+	contextExtractorsMu sync.RWMutex
+	contextExtractors   = []ContextExtractor{TraceparentExtractor}
+)
+
+// RegisterContextExtractor adds an extractor used by the context aware constructors. TraceparentExtractor
+// is registered by default.
+func RegisterContextExtractor(extractor ContextExtractor) {
+	contextExtractorsMu.Lock()
+	defer contextExtractorsMu.Unlock()
+	contextExtractors = append(contextExtractors, extractor)
+}
+
+// NewWithMsgCtx returns a new WrappedErrorImpl with the provided message, the provided fields and the
+// fields found in the context.
+func NewWithMsgCtx(ctx context.Context, message string, fields map[string]interface{}) *WrappedErrorImpl {
+	return Msg(message).Fields(fields).Ctx(ctx).build()
+}
+
+// NewWithErrorCtx returns a new WrappedErrorImpl with the provided error, the provided fields and the
+// fields found in the context.
+func NewWithErrorCtx(ctx context.Context, actual error, fields map[string]interface{}) *WrappedErrorImpl {
+	return From(actual).Fields(fields).Ctx(ctx).build()
+}
+
+// WithErrorCtx takes the previous error, the actual error, the fields associated with it and a context
+// and returns a new WrappedErrorImpl that also has the fields found in the context.
+func WithErrorCtx(ctx context.Context, previous error, actual error, fields map[string]interface{}) *WrappedErrorImpl {
+	return From(actual).Wrap(previous).Fields(fields).Ctx(ctx).build()
+}
+
+// contextFields returns the fields found in ctx by every registered extractor. When two extractors find
+// the same field, the one registered last wins.
+func contextFields(ctx context.Context) map[string]interface{} {
+	contextExtractorsMu.RLock()
+	defer contextExtractorsMu.RUnlock()
+	fields := map[string]interface{}{}
+	for _, extractor := range contextExtractors {
+		for k, v := range extractor(ctx) {
+			fields[k] = v
+		}
+	}
+	return fields
+}
+
+// ContextWithTraceparent returns a copy of ctx that holds a W3C traceparent header value.
+func ContextWithTraceparent(ctx context.Context, traceparent string) context.Context {
+	return context.WithValue(ctx, traceparentKey{}, traceparent)
+}
+
+// TraceparentExtractor parses the W3C traceparent value stored with ContextWithTraceparent and returns
+// its trace id and parent span id as the TraceIDField and SpanIDField fields. It returns no fields when
+// there is no value or it is not valid.
+func TraceparentExtractor(ctx context.Context) map[string]interface{} {
+	traceparent, _ := ctx.Value(traceparentKey{}).(string)
+	traceID, spanID, ok := parseTraceparent(traceparent)
+	if !ok {
+		return nil
+	}
+	return map[string]interface{}{
+		TraceIDField: traceID,
+		SpanIDField:  spanID,
+	}
+}
+
+// parseTraceparent parses a version-traceid-parentid-flags value, such as
+// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01.
+func parseTraceparent(traceparent string) (string, string, bool) {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 {
+		return "", "", false
+	}
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+	if !isLowerHex(version, 2) || version == "ff" || (version == "00" && len(parts) != 4) {
+		return "", "", false
+	}
+	if !isLowerHex(traceID, 32) || !isLowerHex(spanID, 16) || !isLowerHex(flags, 2) {
+		return "", "", false
+	}
+	if strings.Trim(traceID, "0") == "" || strings.Trim(spanID, "0") == "" {
+		return "", "", false
+	}
+	return traceID, spanID, true
+}
+
+func isLowerHex(s string, length int) bool {
+	if len(s) != length || strings.ToLower(s) != s {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
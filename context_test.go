@@ -0,0 +1,74 @@
+package errors
+
+import (
+	"context"
+	goerr "errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type tenantKey struct{}
+
+func TestContextFields(t *testing.T) {
+	RegisterContextExtractor(func(ctx context.Context) map[string]interface{} {
+		if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+			return map[string]interface{}{"tenant": tenant}
+		}
+		return nil
+	})
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	ctx = ContextWithTraceparent(ctx, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	expected := map[string]interface{}{
+		"tenant":     "acme",
+		TraceIDField: "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanIDField:  "00f067aa0ba902b7",
+		"user_id":    42,
+	}
+
+	t.Run("should add the fields found in the context", func(t *testing.T) {
+		fields := map[string]interface{}{"user_id": 42}
+		assert.Equal(t, expected, NewWithMsgCtx(ctx, "actual", fields).GetFields())
+		assert.Equal(t, expected, NewWithErrorCtx(ctx, goerr.New("actual"), fields).GetFields())
+
+		err := WithErrorCtx(ctx, goerr.New("previous"), goerr.New("actual"), fields)
+		assert.Equal(t, expected, err.GetFields())
+		assert.Equal(t, "github.com/hantonelli/errors/context_test.go:34", err.location().String())
+	})
+
+	t.Run("should let explicit fields win", func(t *testing.T) {
+		err := Msg("actual").With("tenant", "explicit").Ctx(ctx).Err()
+		assert.Equal(t, "explicit", err.GetFields()["tenant"])
+	})
+
+	t.Run("should add nothing for an empty context", func(t *testing.T) {
+		err := NewWithMsgCtx(context.Background(), "actual", nil)
+		assert.Equal(t, map[string]interface{}{}, err.GetFields())
+	})
+}
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		traceparent string
+		ok          bool
+	}{
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true},
+		{"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future", true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", false},
+		{"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false},
+		{"00-00000000000000000000000000000000-00f067aa0ba902b7-01", false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false},
+		{"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01", false},
+		{"not a traceparent", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		traceID, spanID, ok := parseTraceparent(tt.traceparent)
+		assert.Equal(t, tt.ok, ok, tt.traceparent)
+		if tt.ok {
+			assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+			assert.Equal(t, "00f067aa0ba902b7", spanID)
+		}
+	}
+}
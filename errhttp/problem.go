@@ -0,0 +1,167 @@
+// Package errhttp renders wrapped errors as RFC 7807 problem details and adapts handlers that return
+// errors to net/http.
+package errhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/hantonelli/errors"
+)
+
+// ContentType is the media type of the problem details written by Render.
+const ContentType = "application/problem+json"
+
+// HandlerFunc is an HTTP handler that returns an error instead of writing it.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// ServeHTTP calls f and renders the returned error, if any, with DefaultRenderer.
+func (f HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	DefaultRenderer.Handler(f).ServeHTTP(w, r)
+}
+
+// DefaultRenderer is the renderer used by HandlerFunc.
+var DefaultRenderer = Renderer{}
+
+// Renderer writes errors as problem details. The status comes from errors.HTTPStatus and the detail from
+// the actual error of the outermost wrapped layer, so locations and fields never leak through it. The
+// detail is only written for statuses below 500 or in debug mode, as the message of a server error,
+// such as a driver error, is meant for the logs.
+type Renderer struct {
+	// TypeBaseURI is prefixed to the code of the chain to build the problem type. The type is
+	// about:blank when it is empty or the chain has no code.
+	TypeBaseURI string
+	// Fields are the names of the fields of the chain written as extension members.
+	Fields []string
+	// Debug adds the location of the outermost layer and the stack of the chain as extension members.
+	Debug bool
+}
+
+// Handler returns an http.Handler that calls f and renders the returned error, if any.
+func (rd Renderer) Handler(f HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := f(w, r); err != nil {
+			rd.Render(w, r, err)
+		}
+	})
+}
+
+// Render writes err as an application/problem+json response.
+func (rd Renderer) Render(w http.ResponseWriter, r *http.Request, err error) {
+	problem := rd.Problem(r, err)
+	body, jsonErr := json.Marshal(problem)
+	if jsonErr != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(problem.Status)
+	w.Write(body)
+}
+
+// Problem returns the problem details of err for the request r.
+func (rd Renderer) Problem(r *http.Request, err error) Problem {
+	status := errors.HTTPStatus(err)
+	problem := Problem{
+		Type:       "about:blank",
+		Title:      http.StatusText(status),
+		Status:     status,
+		Instance:   r.URL.Path,
+		Extensions: map[string]interface{}{},
+	}
+	if code := errors.CodeOf(err); code != "" {
+		problem.Extensions["code"] = code
+		if rd.TypeBaseURI != "" {
+			problem.Type = rd.TypeBaseURI + string(code)
+		}
+	}
+	outermost, hasWrappedLayer := errors.Find(err, func(l errors.Layer) bool {
+		_, isWrappedError := l.Err.(errors.WrappedError)
+		return isWrappedError
+	})
+	if status < http.StatusInternalServerError || rd.Debug {
+		if hasWrappedLayer {
+			problem.Detail = actualMessage(outermost.Actual)
+		} else {
+			problem.Detail = err.Error()
+		}
+	}
+	if len(rd.Fields) > 0 {
+		if fielded, _, ok := errors.Contains[fieldsCarrier](err); ok {
+			allFields := fielded.GetAllFields()
+			for _, name := range rd.Fields {
+				if v, ok := allFields[name]; ok {
+					problem.Extensions[name] = v
+				}
+			}
+		}
+	}
+	if rd.Debug {
+		if hasWrappedLayer {
+			problem.Extensions["location"] = outermost.Location
+		}
+		if we, _, ok := errors.Contains[errors.WrappedError](err); ok {
+			stack := []string{}
+			for _, frame := range we.GetStackFrames() {
+				stack = append(stack, fmt.Sprintf("%v %s.%s", frame, frame.Package, frame.Function))
+			}
+			problem.Extensions["stack"] = stack
+		}
+	}
+	return problem
+}
+
+// actualMessage returns the message of err, or of its actual error when it is a wrapped error, so the
+// locations and fields of its rendering are left out.
+func actualMessage(err error) string {
+	for {
+		we, ok := err.(errors.WrappedError)
+		if !ok {
+			return err.Error()
+		}
+		err = we.GetActual()
+	}
+}
+
+type fieldsCarrier interface {
+	GetAllFields() map[string]interface{}
+}
+
+// Problem holds the members of an RFC 7807 problem details object.
+type Problem struct {
+	Type     string
+	Title    string
+	Status   int
+	Detail   string
+	Instance string
+	// Extensions are written as additional members. Members that would replace a standard one are skipped.
+	Extensions map[string]interface{}
+}
+
+// MarshalJSON writes the standard members followed by the extension members. Extension values that
+// cannot be encoded are written in their %v form, or as their type name when they are cyclic.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]json.RawMessage, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			raw, _ = json.Marshal(errors.SprintValue(v))
+		}
+		members[k] = raw
+	}
+	members["type"], _ = json.Marshal(p.Type)
+	members["title"], _ = json.Marshal(p.Title)
+	members["status"], _ = json.Marshal(p.Status)
+	if p.Detail != "" {
+		members["detail"], _ = json.Marshal(p.Detail)
+	} else {
+		delete(members, "detail")
+	}
+	if p.Instance != "" {
+		members["instance"], _ = json.Marshal(p.Instance)
+	} else {
+		delete(members, "instance")
+	}
+	return json.Marshal(members)
+}
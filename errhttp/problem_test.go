@@ -0,0 +1,142 @@
+package errhttp
+
+import (
+	"encoding/json"
+	goerr "errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hantonelli/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func serve(t *testing.T, h http.Handler) (*http.Response, map[string]interface{}) {
+	t.Helper()
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/users/42")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	body := map[string]interface{}{}
+	if resp.Header.Get("Content-Type") == ContentType {
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	}
+	return resp, body
+}
+
+func TestHandlerFunc(t *testing.T) {
+	errors.RegisterHTTPStatus("errhttp_not_found", http.StatusNotFound)
+
+	t.Run("should not render anything when there is no error", func(t *testing.T) {
+		resp, _ := serve(t, HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			w.WriteHeader(http.StatusNoContent)
+			return nil
+		}))
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+
+	t.Run("should render the chain as problem details", func(t *testing.T) {
+		resp, body := serve(t, HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			notFound := errors.NewWithMsgAndFields("user not found", map[string]interface{}{"user_id": 42}).WithCode("errhttp_not_found")
+			return fmt.Errorf("get user: %w", notFound)
+		}))
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, ContentType, resp.Header.Get("Content-Type"))
+		assert.Equal(t, map[string]interface{}{
+			"type":     "about:blank",
+			"title":    "Not Found",
+			"status":   float64(404),
+			"detail":   "user not found",
+			"instance": "/users/42",
+			"code":     "errhttp_not_found",
+		}, body)
+	})
+
+	t.Run("should render an error that is not wrapped with the default status", func(t *testing.T) {
+		resp, body := serve(t, HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			return goerr.New("boom")
+		}))
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		assert.NotContains(t, body, "detail")
+		assert.Equal(t, "Internal Server Error", body["title"])
+	})
+
+	t.Run("should not write the detail of a wrapped server error", func(t *testing.T) {
+		resp, body := serve(t, HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			return errors.NewWithError(goerr.New("pq: password authentication failed for user admin at 10.0.0.3"))
+		}))
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		assert.NotContains(t, body, "detail")
+	})
+
+	t.Run("should write the message of an actual error that is itself wrapped", func(t *testing.T) {
+		inner := errors.NewWithMsgAndFields("user not found", map[string]interface{}{"user_id": 42}).WithCode("errhttp_not_found")
+		resp, body := serve(t, HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			return errors.From(inner).Err()
+		}))
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, "user not found", body["detail"])
+
+		_, body = serve(t, Renderer{Debug: true}.Handler(func(w http.ResponseWriter, r *http.Request) error {
+			return errors.From(errors.NewWithMsgAndFields("inner", map[string]interface{}{"k": 1})).Err()
+		}))
+		assert.Equal(t, "inner", body["detail"])
+	})
+
+	t.Run("should write the detail of an error that is not wrapped in debug mode", func(t *testing.T) {
+		rd := Renderer{Debug: true}
+		_, body := serve(t, rd.Handler(func(w http.ResponseWriter, r *http.Request) error {
+			return goerr.New("pq: connection refused")
+		}))
+		assert.Equal(t, "pq: connection refused", body["detail"])
+	})
+}
+
+func TestRenderer(t *testing.T) {
+	errors.RegisterHTTPStatus("errhttp_not_found", http.StatusNotFound)
+	handler := func(w http.ResponseWriter, r *http.Request) error {
+		return errors.NewWithMsgAndFields("user not found", map[string]interface{}{
+			"user_id": 42,
+			"status":  "hidden",
+			"secret":  "hidden",
+		}).WithCode("errhttp_not_found")
+	}
+
+	t.Run("should build the type and write the selected fields", func(t *testing.T) {
+		rd := Renderer{TypeBaseURI: "https://example.com/problems/", Fields: []string{"user_id", "status", "missing"}}
+		resp, body := serve(t, rd.Handler(handler))
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, "https://example.com/problems/errhttp_not_found", body["type"])
+		assert.Equal(t, float64(42), body["user_id"])
+		assert.Equal(t, float64(404), body["status"])
+		assert.NotContains(t, body, "secret")
+		assert.NotContains(t, body, "missing")
+		assert.NotContains(t, body, "stack")
+		assert.NotContains(t, body, "location")
+	})
+
+	t.Run("should write the location and stack in debug mode", func(t *testing.T) {
+		rd := Renderer{Debug: true}
+		_, body := serve(t, rd.Handler(handler))
+		assert.Contains(t, body["location"], "github.com/hantonelli/errors/errhttp/problem_test.go:")
+		assert.NotEmpty(t, body["stack"])
+	})
+}
+
+func TestProblemMarshalJSON(t *testing.T) {
+	t.Run("should write extensions that cannot be encoded in their %v form", func(t *testing.T) {
+		cyclic := map[string]interface{}{}
+		cyclic["self"] = cyclic
+		b, err := json.Marshal(Problem{Type: "about:blank", Title: "Internal Server Error", Status: 500,
+			Extensions: map[string]interface{}{"cyclic": cyclic, "func": func() {}}})
+		assert.NoError(t, err)
+
+		var body map[string]interface{}
+		assert.NoError(t, json.Unmarshal(b, &body))
+		assert.Equal(t, "map[string]interface {}", body["cyclic"])
+		assert.Regexp(t, "^0x[0-9a-f]+$", body["func"])
+	})
+}
@@ -0,0 +1,215 @@
+// Package errlocale renders the messages of wrapped errors in the language of the user, from catalogs
+// of translations keyed by the message keys of the errors.
+package errlocale
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/language"
+
+	"github.com/hantonelli/errors"
+)
+
+// Catalog keeps translated messages by language and message key. A message is a template filled in from
+// the fields of the layer, such as "usuario {user_id} no encontrado", see errors.NewWithTemplate.
+type Catalog struct {
+	defaultTag language.Tag
+
+	mu       sync.RWMutex
+	messages map[language.Tag]map[string]string
+	tags     []language.Tag
+	matcher  language.Matcher
+}
+
+// NewCatalog returns an empty catalog that falls back to defaultTag when a message has no translation
+// in the language asked for.
+func NewCatalog(defaultTag language.Tag) *Catalog {
+	c := &Catalog{defaultTag: defaultTag, messages: map[language.Tag]map[string]string{}}
+	c.addMessages(defaultTag, nil)
+	return c
+}
+
+// Add adds the message of a key in a language.
+func (c *Catalog) Add(tag language.Tag, key, message string) {
+	c.addMessages(tag, map[string]string{key: message})
+}
+
+// LoadJSON adds the messages of a language from a JSON object of message keys to messages.
+func (c *Catalog) LoadJSON(tag language.Tag, r io.Reader) error {
+	var messages map[string]string
+	if err := json.NewDecoder(r).Decode(&messages); err != nil {
+		return fmt.Errorf("errlocale: decoding %s messages: %w", tag, err)
+	}
+	c.addMessages(tag, messages)
+	return nil
+}
+
+// LoadPO adds the messages of a language from a gettext PO file, where msgid is the message key and
+// msgstr the message. The header and the entries without translation are ignored, and so are the
+// plural forms other than msgstr[0].
+func (c *Catalog) LoadPO(tag language.Tag, r io.Reader) error {
+	messages, err := parsePO(r)
+	if err != nil {
+		return fmt.Errorf("errlocale: parsing %s messages: %w", tag, err)
+	}
+	c.addMessages(tag, messages)
+	return nil
+}
+
+// LoadFile adds the messages of a .json or .po file whose name, without the extension, is the
+// language, such as es-AR.json.
+func (c *Catalog) LoadFile(path string) error {
+	ext := filepath.Ext(path)
+	tag, err := language.Parse(strings.TrimSuffix(filepath.Base(path), ext))
+	if err != nil {
+		return fmt.Errorf("errlocale: language of %s: %w", path, err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("errlocale: %w", err)
+	}
+	defer f.Close()
+
+	switch ext {
+	case ".json":
+		return c.LoadJSON(tag, f)
+	case ".po":
+		return c.LoadPO(tag, f)
+	}
+	return fmt.Errorf("errlocale: unsupported file %s", path)
+}
+
+// LoadDir adds the messages of every .json and .po file in dir, see LoadFile.
+func (c *Catalog) LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("errlocale: %w", err)
+	}
+	for _, entry := range entries {
+		if ext := filepath.Ext(entry.Name()); entry.IsDir() || (ext != ".json" && ext != ".po") {
+			continue
+		}
+		if err := c.LoadFile(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Catalog) addMessages(tag language.Tag, messages map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.messages[tag]; !ok {
+		c.messages[tag] = map[string]string{}
+		c.tags = append(c.tags, tag)
+		c.matcher = language.NewMatcher(c.tags)
+	}
+	for key, message := range messages {
+		c.messages[tag][key] = message
+	}
+}
+
+// Message returns the message of the key in the language that best matches tag, trying its parent
+// languages and then the default language when it has no translation.
+func (c *Catalog) Message(tag language.Tag, key string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if _, index, confidence := c.matcher.Match(tag); confidence != language.No {
+		for t := c.tags[index]; ; t = t.Parent() {
+			if message, ok := c.messages[t][key]; ok {
+				return message, true
+			}
+			if t == language.Und {
+				break
+			}
+		}
+	}
+	message, ok := c.messages[c.defaultTag][key]
+	return message, ok
+}
+
+// Localize returns the messages of the layers of the chain that have a message key, from the
+// outermost one, translated to tag and joined with ": ". A layer whose key has no translation in any
+// language keeps its own message. It returns false when no layer has a message key.
+func (c *Catalog) Localize(err error, tag language.Tag) (string, bool) {
+	var messages []string
+	errors.Walk(err, func(l errors.Layer) bool {
+		keyed, ok := l.Err.(interface{ GetMessageKey() string })
+		if !ok || keyed.GetMessageKey() == "" {
+			return true
+		}
+		message, ok := c.Message(tag, keyed.GetMessageKey())
+		if !ok {
+			messages = append(messages, l.Actual.Error())
+			return true
+		}
+		messages = append(messages, errors.NewTemplateMessage(message, l.Fields).Error())
+		return true
+	})
+	return strings.Join(messages, ": "), len(messages) > 0
+}
+
+// parsePO returns the translated messages of a PO file by msgid.
+func parsePO(r io.Reader) (map[string]string, error) {
+	messages := map[string]string{}
+	var msgid, msgstr *strings.Builder
+	var current *strings.Builder
+	flush := func() {
+		if msgid != nil && msgstr != nil && msgid.Len() > 0 && msgstr.Len() > 0 {
+			messages[msgid.String()] = msgstr.String()
+		}
+		msgid, msgstr, current = nil, nil, nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		keyword, rest, _ := strings.Cut(line, " ")
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, `"`):
+			if current == nil {
+				return nil, fmt.Errorf("line %d: string without keyword", n)
+			}
+			rest = line
+		case keyword == "msgctxt":
+			flush()
+			current = &strings.Builder{}
+		case keyword == "msgid":
+			if msgstr != nil || msgid != nil {
+				flush()
+			}
+			msgid = &strings.Builder{}
+			current = msgid
+		case keyword == "msgid_plural" || (strings.HasPrefix(keyword, "msgstr[") && keyword != "msgstr[0]"):
+			current = &strings.Builder{}
+		case keyword == "msgstr" || keyword == "msgstr[0]":
+			if msgid == nil {
+				return nil, fmt.Errorf("line %d: msgstr without msgid", n)
+			}
+			msgstr = &strings.Builder{}
+			current = msgstr
+		default:
+			return nil, fmt.Errorf("line %d: unknown keyword %q", n, keyword)
+		}
+		s, err := strconv.Unquote(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		current.WriteString(s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return messages, nil
+}
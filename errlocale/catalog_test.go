@@ -0,0 +1,133 @@
+package errlocale
+
+import (
+	goerr "errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+
+	"github.com/hantonelli/errors"
+)
+
+const esPO = `# Spanish translations
+msgid ""
+msgstr ""
+"Content-Type: text/plain; charset=UTF-8\n"
+
+#: users.go:12
+msgid "user.not_found"
+msgstr "usuario {user_id} no encontrado"
+
+msgctxt "checkout"
+msgid "order.failed"
+msgstr ""
+"no se pudo "
+"procesar el pedido"
+
+msgid "untranslated"
+msgstr ""
+
+msgid "item.count"
+msgid_plural "item.count"
+msgstr[0] "un artículo"
+msgstr[1] "{n} artículos"
+`
+
+func newTestCatalog(t *testing.T) *Catalog {
+	c := NewCatalog(language.English)
+	assert.NoError(t, c.LoadJSON(language.English, strings.NewReader(
+		`{"user.not_found": "user {user_id} not found", "order.failed": "the order could not be processed", "only.english": "only in english"}`)))
+	assert.NoError(t, c.LoadPO(language.Spanish, strings.NewReader(esPO)))
+	c.Add(language.MustParse("es-AR"), "order.failed", "no pudimos procesar el pedido")
+	return c
+}
+
+func TestCatalogMessage(t *testing.T) {
+	c := newTestCatalog(t)
+
+	t.Run("should return the message of the language", func(t *testing.T) {
+		message, ok := c.Message(language.Spanish, "user.not_found")
+		assert.True(t, ok)
+		assert.Equal(t, "usuario {user_id} no encontrado", message)
+	})
+
+	t.Run("should read continuation lines, contexts and plurals of po files", func(t *testing.T) {
+		message, _ := c.Message(language.Spanish, "order.failed")
+		assert.Equal(t, "no se pudo procesar el pedido", message)
+		message, _ = c.Message(language.Spanish, "item.count")
+		assert.Equal(t, "un artículo", message)
+	})
+
+	t.Run("should prefer the regional language and fall back to its parent", func(t *testing.T) {
+		message, _ := c.Message(language.MustParse("es-AR"), "order.failed")
+		assert.Equal(t, "no pudimos procesar el pedido", message)
+		message, _ = c.Message(language.MustParse("es-AR"), "user.not_found")
+		assert.Equal(t, "usuario {user_id} no encontrado", message)
+	})
+
+	t.Run("should fall back to the default language", func(t *testing.T) {
+		message, ok := c.Message(language.Spanish, "only.english")
+		assert.True(t, ok)
+		assert.Equal(t, "only in english", message)
+
+		message, ok = c.Message(language.Spanish, "untranslated")
+		assert.False(t, ok)
+		assert.Equal(t, "", message)
+
+		message, _ = c.Message(language.Japanese, "user.not_found")
+		assert.Equal(t, "user {user_id} not found", message)
+	})
+}
+
+func TestCatalogLocalize(t *testing.T) {
+	c := newTestCatalog(t)
+	root := errors.NewWithKey("user.not_found", "user not found", map[string]interface{}{"user_id": 42})
+	err := errors.Msg("checkout failed").MessageKey("order.failed").Wrap(errors.WithError(root, goerr.New("lookup"))).Err()
+
+	t.Run("should translate every layer with a message key", func(t *testing.T) {
+		message, ok := c.Localize(err, language.Spanish)
+		assert.True(t, ok)
+		assert.Equal(t, "no se pudo procesar el pedido: usuario 42 no encontrado", message)
+
+		message, _ = c.Localize(err, language.English)
+		assert.Equal(t, "the order could not be processed: user 42 not found", message)
+	})
+
+	t.Run("should keep the message of a key without translation", func(t *testing.T) {
+		message, ok := c.Localize(errors.NewWithKey("unknown.key", "something failed", nil), language.Spanish)
+		assert.True(t, ok)
+		assert.Equal(t, "something failed", message)
+	})
+
+	t.Run("should report chains without message keys", func(t *testing.T) {
+		message, ok := c.Localize(errors.NewWithMsg("internal"), language.Spanish)
+		assert.False(t, ok)
+		assert.Equal(t, "", message)
+	})
+}
+
+func TestCatalogLoad(t *testing.T) {
+	t.Run("should load every file of a directory by its language", func(t *testing.T) {
+		dir := t.TempDir()
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "en.json"), []byte(`{"user.not_found": "user {user_id} not found"}`), 0o600))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "es.po"), []byte(esPO), 0o600))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("ignored"), 0o600))
+
+		c := NewCatalog(language.English)
+		assert.NoError(t, c.LoadDir(dir))
+		message, _ := c.Message(language.Spanish, "user.not_found")
+		assert.Equal(t, "usuario {user_id} no encontrado", message)
+	})
+
+	t.Run("should report invalid files", func(t *testing.T) {
+		c := NewCatalog(language.English)
+		assert.Error(t, c.LoadJSON(language.English, strings.NewReader(`["not", "an", "object"]`)))
+		assert.EqualError(t, c.LoadPO(language.Spanish, strings.NewReader(`msgstr "sin msgid"`)),
+			"errlocale: parsing es messages: line 1: msgstr without msgid")
+		assert.Error(t, c.LoadFile(filepath.Join(t.TempDir(), "not-a-language!.json")))
+	})
+}
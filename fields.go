@@ -0,0 +1,109 @@
+package errors
+
+import "sync/atomic"
+
+// MergePolicy specifies how GetAllFields merges a field set in several layers of a chain.
+type MergePolicy int32
+
+const (
+	// OuterWins keeps the value of the layer closest to the outermost error.
+	OuterWins MergePolicy = iota
+	// InnerWins keeps the value of the layer closest to the root error.
+	InnerWins
+	// KeepAll keeps every value, from the outermost layer to the root, as a []interface{}. A field set
+	// in a single layer keeps its value as it is.
+	KeepAll
+)
+
+var mergePolicy int32
+
+// SetMergePolicy sets how GetAllFields merges a field set in several layers from then on.
+func SetMergePolicy(policy MergePolicy) {
+	atomic.StoreInt32(&mergePolicy, int32(policy))
+}
+
+func currentMergePolicy() MergePolicy {
+	return MergePolicy(atomic.LoadInt32(&mergePolicy))
+}
+
+// MergeFields returns the fields of every layer of the chain merged with the provided policy and
+// redacted with the redaction policy.
+func MergeFields(err error, policy MergePolicy) map[string]interface{} {
+	return redactFields(mergeFieldSources(fieldSources(err), policy))
+}
+
+// FieldSource describes a value set for a field and the layer that set it.
+type FieldSource struct {
+	Value interface{}
+	// Message is the message of the actual error of the layer.
+	Message string
+	// Location is where the layer was created.
+	Location string
+	// Depth is the depth of the layer, as reported by Walk.
+	Depth int
+}
+
+// fieldSources returns the original values set for each field by the wrapped layers of the chain, in
+// the order Walk visits them.
+func fieldSources(err error) map[string][]FieldSource {
+	sources := map[string][]FieldSource{}
+	Walk(err, func(l Layer) bool {
+		we, isWrappedError := l.Err.(WrappedError)
+		if !isWrappedError {
+			return true
+		}
+		fields := l.Fields
+		if unredacted, ok := we.(interface {
+			GetFieldsUnredacted() map[string]interface{}
+		}); ok {
+			fields = unredacted.GetFieldsUnredacted()
+		}
+		for k, v := range fields {
+			sources[k] = append(sources[k], FieldSource{
+				Value:    v,
+				Message:  l.Actual.Error(),
+				Location: l.Location,
+				Depth:    l.Depth,
+			})
+		}
+		return true
+	})
+	return sources
+}
+
+// mergeFieldSources picks the value of every field with the policy. Between layers at the same depth,
+// such as the errors of an aggregate, the first one visited wins.
+func mergeFieldSources(sources map[string][]FieldSource, policy MergePolicy) map[string]interface{} {
+	merged := make(map[string]interface{}, len(sources))
+	for k, values := range sources {
+		if policy == KeepAll && len(values) > 1 {
+			all := make([]interface{}, 0, len(values))
+			for _, source := range values {
+				all = append(all, source.Value)
+			}
+			merged[k] = all
+			continue
+		}
+		picked := values[0]
+		for _, source := range values[1:] {
+			if (policy == InnerWins && source.Depth > picked.Depth) || (policy != InnerWins && source.Depth < picked.Depth) {
+				picked = source
+			}
+		}
+		merged[k] = picked.Value
+	}
+	return merged
+}
+
+// redactFieldSources replaces the values of sensitive fields with the redaction policy.
+func redactFieldSources(sources map[string][]FieldSource) map[string][]FieldSource {
+	policy := currentRedactionPolicy()
+	for k, values := range sources {
+		for i := range values {
+			if _, isSensitive := values[i].Value.(Sensitive); isSensitive || policy.IsSensitive(k) {
+				values[i].Value = policy.replacement()
+			}
+		}
+	}
+	return sources
+}
@@ -0,0 +1,90 @@
+package errors
+
+import (
+	goerr "errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getUserChain() *WrappedErrorImpl {
+	root := NewWithMsgAndFields("user not found", map[string]interface{}{"user_id": 1, "table": "users"})
+	middle := WithErrorAndFields(root, goerr.New("load profile"), map[string]interface{}{"user_id": 2})
+	return WithErrorAndFields(middle, goerr.New("handle request"), map[string]interface{}{"user_id": 3, "path": "/me"})
+}
+
+func TestGetAllFieldsMerge(t *testing.T) {
+
+	t.Run("should not change the fields of any layer", func(t *testing.T) {
+		err := getUserChain()
+		err.GetAllFields()
+		err.GetAllFields()
+		assert.Equal(t, map[string]interface{}{"user_id": 3, "path": "/me"}, err.GetFields())
+		middle := err.GetPrevious().(*WrappedErrorImpl)
+		assert.Equal(t, map[string]interface{}{"user_id": 2}, middle.GetFields())
+	})
+
+	t.Run("should return a fresh map", func(t *testing.T) {
+		err := getUserChain()
+		err.GetAllFields()["path"] = "changed"
+		assert.Equal(t, "/me", err.GetAllFields()["path"])
+	})
+
+	t.Run("should keep the outer value by default", func(t *testing.T) {
+		assert.Equal(t, map[string]interface{}{"user_id": 3, "path": "/me", "table": "users"}, getUserChain().GetAllFields())
+	})
+
+	t.Run("should keep the inner value", func(t *testing.T) {
+		SetMergePolicy(InnerWins)
+		defer SetMergePolicy(OuterWins)
+		assert.Equal(t, map[string]interface{}{"user_id": 1, "path": "/me", "table": "users"}, getUserChain().GetAllFields())
+	})
+
+	t.Run("should keep every value", func(t *testing.T) {
+		assert.Equal(t, map[string]interface{}{
+			"user_id": []interface{}{3, 2, 1},
+			"path":    "/me",
+			"table":   "users",
+		}, MergeFields(getUserChain(), KeepAll))
+	})
+
+	t.Run("should keep the first error of an aggregate", func(t *testing.T) {
+		agg := Aggregate(
+			NewWithMsgAndFields("a", map[string]interface{}{"backend": "a"}),
+			NewWithMsgAndFields("b", map[string]interface{}{"backend": "b"}),
+		)
+		assert.Equal(t, "a", agg.GetAllFields()["backend"])
+		assert.Equal(t, "a", MergeFields(agg, InnerWins)["backend"])
+	})
+}
+
+func TestGetFieldsWithProvenance(t *testing.T) {
+
+	t.Run("should return the layer and location of every value", func(t *testing.T) {
+		provenance := getUserChain().GetFieldsWithProvenance()
+
+		assert.Len(t, provenance["user_id"], 3)
+		assert.Equal(t, FieldSource{
+			Value:    3,
+			Message:  "handle request",
+			Location: "github.com/hantonelli/errors/fields_test.go:13",
+			Depth:    0,
+		}, provenance["user_id"][0])
+		assert.Equal(t, 2, provenance["user_id"][1].Value)
+		assert.Equal(t, "load profile", provenance["user_id"][1].Message)
+		assert.Equal(t, "github.com/hantonelli/errors/fields_test.go:11", provenance["user_id"][2].Location)
+		assert.Equal(t, 2, provenance["user_id"][2].Depth)
+		assert.Len(t, provenance["table"], 1)
+	})
+
+	t.Run("should redact sensitive values", func(t *testing.T) {
+		err := NewWithMsgAndFields("login failed", map[string]interface{}{"password": "hunter2"})
+		assert.Equal(t, "[REDACTED]", err.GetFieldsWithProvenance()["password"][0].Value)
+	})
+
+	t.Run("should return the provenance of an aggregate", func(t *testing.T) {
+		agg := Aggregate(NewWithMsgAndFields("a", map[string]interface{}{"backend": "a"}))
+		assert.Equal(t, "a", agg.GetFieldsWithProvenance()["backend"][0].Value)
+		assert.Equal(t, 1, agg.GetFieldsWithProvenance()["backend"][0].Depth)
+	})
+}
@@ -0,0 +1,85 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Format implements fmt.Formatter. %s and %v print the same message as Error(), %q prints it quoted
+// and %+v prints every layer of the chain in its own block followed by the stack trace, one frame per line.
+// Other verbs print the message the way fmt reports a wrong verb, such as %!d(...).
+func (e *WrappedErrorImpl) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			writeVerbose(s, e)
+			return
+		}
+		io.WriteString(s, e.Error())
+	case 's':
+		io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	default:
+		writeBadVerb(s, verb, e)
+	}
+}
+
+// writeBadVerb writes err the way fmt writes an operand that does not support the verb.
+func writeBadVerb(w io.Writer, verb rune, err error) {
+	fmt.Fprintf(w, "%%!%c(%T=%s)", verb, err, err.Error())
+}
+
+func writeVerbose(w io.Writer, e *WrappedErrorImpl) {
+	var err error = e
+	for err != nil {
+		we, isWrappedError := err.(WrappedError)
+		if !isWrappedError {
+			if agg, ok := err.(*AggregateError); ok {
+				writeVerboseAggregate(w, agg)
+			} else {
+				fmt.Fprintf(w, "Message: %s\n", err.Error())
+			}
+			break
+		}
+		fmt.Fprintf(w, "Message: %s\n", we.GetActual().Error())
+		if code := errorCode(we); code != "" {
+			fmt.Fprintf(w, "\tCode: %s\n", code)
+		}
+		if impl, ok := we.(*WrappedErrorImpl); ok {
+			fmt.Fprintf(w, "\tLocation: %v\n", impl.location())
+		}
+		if fields := we.GetFields(); len(fields) > 0 {
+			fmt.Fprintf(w, "\tFields: %s\n", printFields(fields))
+		}
+		err = we.GetPrevious()
+	}
+	if frames := e.GetStackFrames(); len(frames) > 0 {
+		fmt.Fprint(w, "Stacktrace:\n")
+		for _, frame := range frames {
+			fmt.Fprintf(w, "\t%v %s.%s\n", frame, frame.Package, frame.Function)
+		}
+		if e.IsStackTruncated() {
+			fmt.Fprintf(w, "\t%s\n", truncatedMarker)
+		}
+	}
+}
+
+func writeVerboseAggregate(w io.Writer, agg *AggregateError) {
+	fmt.Fprintf(w, "Errors: %d\n", len(agg.errs))
+	for i, err := range agg.errs {
+		fmt.Fprintf(w, "[%d]\n", i+1)
+		var child string
+		if _, ok := err.(fmt.Formatter); ok {
+			child = fmt.Sprintf("%+v", err)
+		} else {
+			child = fmt.Sprintf("Message: %s\n", err.Error())
+		}
+		for _, line := range strings.SplitAfter(child, "\n") {
+			if line != "" {
+				fmt.Fprintf(w, "\t%s", line)
+			}
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package errors
+
+import (
+	goerr "errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+
+	t.Run("should print the error message with %s and %v", func(t *testing.T) {
+		err := WithErrorAndFields(goerr.New("previous"), goerr.New("actual"), fields)
+		assert.Equal(t, err.Error(), fmt.Sprintf("%s", err))
+		assert.Equal(t, err.Error(), fmt.Sprintf("%v", err))
+	})
+
+	t.Run("should report other verbs the way fmt does", func(t *testing.T) {
+		err := NewWithMsg("actual")
+		assert.Equal(t, "[%!d(*errors.WrappedErrorImpl="+err.Error()+")]", fmt.Sprintf("[%d]", err))
+
+		agg := Aggregate(goerr.New("a"))
+		assert.Equal(t, "%!x(*errors.AggregateError="+agg.Error()+")", fmt.Sprintf("%x", agg))
+	})
+
+	t.Run("should print the error message quoted with %q", func(t *testing.T) {
+		err := NewWithMsg("actual")
+		assert.Equal(t, fmt.Sprintf("%q", err.Error()), fmt.Sprintf("%q", err))
+	})
+
+	t.Run("should print every layer and the stacktrace with %+v", func(t *testing.T) {
+		err := getThirdWrap()
+		expectedLayers := `Message: third wrap
+	Location: github.com/hantonelli/errors/wrappederror_helper_test.go:18
+	Fields: map[third-wrap-number:123 third-wrap-string:test-string]
+Message: second wrap
+	Location: github.com/hantonelli/errors/wrappederror_helper_test.go:27
+	Fields: map[second-wrap-number:123 second-wrap-string:test-string]
+Message: previous
+	Location: github.com/hantonelli/errors/wrappederror_helper_test.go:35
+	Fields: map[first-wrap-number:123 first-wrap-string:test-string]
+Stacktrace:
+	github.com/hantonelli/errors/wrappederror_helper_test.go:35 github.com/hantonelli/errors.getFirstWrapped
+	github.com/hantonelli/errors/wrappederror_helper_test.go:25 github.com/hantonelli/errors.getSecondWrap
+	github.com/hantonelli/errors/wrappederror_helper_test.go:16 github.com/hantonelli/errors.getThirdWrap
+`
+		verbose := fmt.Sprintf("%+v", err)
+		assert.True(t, strings.HasPrefix(verbose, expectedLayers), verbose)
+	})
+
+	t.Run("should print a previous error that is not wrapped with %+v", func(t *testing.T) {
+		err := WithError(goerr.New("previous"), goerr.New("actual"))
+		verbose := fmt.Sprintf("%+v", err)
+		assert.Contains(t, verbose, "Message: actual\n")
+		assert.Contains(t, verbose, "Message: previous\n")
+		assert.NotContains(t, verbose, "Stacktrace:")
+	})
+}
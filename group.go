@@ -0,0 +1,94 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrTaskFailed is the actual error of the layer that Group adds, with the fields of the task, on top of
+// the error of every task that failed.
+var ErrTaskFailed = errors.New("task failed")
+
+// Group runs tasks in goroutines and collects the error of every task that fails, unlike errgroup which
+// keeps only the first one. A zero Group has no limit and does not cancel any context.
+type Group struct {
+	cancel        context.CancelCauseFunc
+	cancelOnError bool
+	sem           chan struct{}
+	wg            sync.WaitGroup
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// GroupWithContext returns a new Group and a context derived from ctx, which is canceled when Wait
+// returns or, after SetCancelOnError, when the first task fails.
+func GroupWithContext(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &Group{cancel: cancel}, ctx
+}
+
+// SetCancelOnError sets whether the context of the group is canceled as soon as a task fails.
+func (g *Group) SetCancelOnError(cancel bool) {
+	g.cancelOnError = cancel
+}
+
+// SetLimit limits the number of tasks running at once to n, so Go blocks until a task finishes. A
+// negative n removes the limit. It must not be called while tasks are running.
+func (g *Group) SetLimit(n int) {
+	if n < 0 {
+		g.sem = nil
+		return
+	}
+	g.sem = make(chan struct{}, n)
+}
+
+// Go runs fn in a new goroutine. When fn fails or panics, its error is kept by the group with the
+// provided fields added in an ErrTaskFailed layer located where Go is called.
+func (g *Group) Go(fields map[string]interface{}, fn func() error) {
+	pc := callerPC(1)
+	fields = copyFields(fields)
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+
+	g.mu.Lock()
+	index := len(g.errs)
+	g.errs = append(g.errs, nil)
+	g.mu.Unlock()
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		defer func() {
+			if g.sem != nil {
+				<-g.sem
+			}
+		}()
+
+		err := runRecovered(fn)
+		if err == nil {
+			return
+		}
+		failed := &WrappedErrorImpl{actual: ErrTaskFailed, previous: err, fields: fields, pc: pc}
+		g.mu.Lock()
+		g.errs[index] = failed
+		g.mu.Unlock()
+		if g.cancelOnError && g.cancel != nil {
+			g.cancel(failed)
+		}
+	}()
+}
+
+// Wait waits for every task and returns an AggregateError with the errors of the tasks that failed, in
+// the order the tasks were started, or nil when none failed.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	if g.cancel != nil {
+		g.cancel(nil)
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return Combine(g.errs...)
+}
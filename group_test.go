@@ -0,0 +1,117 @@
+package errors
+
+import (
+	"context"
+	goerr "errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup(t *testing.T) {
+	errBackend := goerr.New("backend failed")
+
+	t.Run("should return nil when every task succeeds", func(t *testing.T) {
+		var g Group
+		for i := 0; i < 5; i++ {
+			g.Go(nil, func() error {
+				return nil
+			})
+		}
+		assert.NoError(t, g.Wait())
+	})
+
+	t.Run("should aggregate every failure with its fields and chain", func(t *testing.T) {
+		var g Group
+		g.Go(map[string]interface{}{"backend": "a"}, func() error {
+			return NewWithMsgAndFields("timeout", map[string]interface{}{"after": "1s"})
+		})
+		g.Go(map[string]interface{}{"backend": "b"}, func() error {
+			return nil
+		})
+		g.Go(map[string]interface{}{"backend": "c"}, func() error {
+			return errBackend
+		})
+		g.Go(map[string]interface{}{"backend": "d"}, func() error {
+			panic("boom")
+		})
+
+		err := g.Wait()
+		agg, ok := err.(*AggregateError)
+		assert.True(t, ok)
+		assert.Len(t, agg.Errors(), 3)
+
+		first := agg.Errors()[0].(*WrappedErrorImpl)
+		assert.Equal(t, ErrTaskFailed, first.GetActual())
+		assert.Equal(t, map[string]interface{}{"backend": "a"}, first.GetFields())
+		assert.Equal(t, "github.com/hantonelli/errors/group_test.go:28", first.location().String())
+		assert.Equal(t, map[string]interface{}{"backend": "a", "after": "1s"}, first.GetAllFields())
+		assert.NotEmpty(t, first.GetStackFrames())
+
+		assert.True(t, goerr.Is(err, errBackend))
+		assert.True(t, goerr.Is(err, ErrTaskFailed))
+		_, fields, ok := ContainsError(errBackend, err)
+		assert.True(t, ok)
+		assert.Equal(t, map[string]interface{}{"backend": "c"}, fields)
+
+		panicked := agg.Errors()[2].(*WrappedErrorImpl)
+		assert.Equal(t, "boom", panicked.GetAllFields()[PanicValueField])
+		assert.Equal(t, "d", panicked.GetAllFields()["backend"])
+	})
+
+	t.Run("should cancel the context on the first failure", func(t *testing.T) {
+		g, ctx := GroupWithContext(context.Background())
+		g.SetCancelOnError(true)
+		g.Go(nil, func() error {
+			return errBackend
+		})
+		g.Go(nil, func() error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		err := g.Wait()
+		assert.True(t, goerr.Is(err, errBackend))
+		assert.True(t, goerr.Is(err, context.Canceled))
+		assert.True(t, goerr.Is(context.Cause(ctx), errBackend))
+	})
+
+	t.Run("should not cancel the context on failure by default", func(t *testing.T) {
+		g, ctx := GroupWithContext(context.Background())
+		g.Go(nil, func() error {
+			return errBackend
+		})
+		g.Go(nil, func() error {
+			time.Sleep(10 * time.Millisecond)
+			return ctx.Err()
+		})
+
+		err := g.Wait()
+		assert.Len(t, err.(*AggregateError).Errors(), 1)
+		assert.Error(t, ctx.Err())
+	})
+
+	t.Run("should limit the tasks running at once", func(t *testing.T) {
+		var g Group
+		g.SetLimit(2)
+		var running, maxRunning int32
+		for i := 0; i < 10; i++ {
+			g.Go(nil, func() error {
+				n := atomic.AddInt32(&running, 1)
+				for {
+					m := atomic.LoadInt32(&maxRunning)
+					if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				return nil
+			})
+		}
+		assert.NoError(t, g.Wait())
+		assert.LessOrEqual(t, maxRunning, int32(2))
+	})
+}
@@ -0,0 +1,11 @@
+package errors
+
+import "iter"
+
+// Layers returns an iterator over the layers of the chain, visited in the same order as Walk, so a
+// chain mixing wrapped errors and errors that support Unwrap can be read in a single loop.
+func Layers(err error) iter.Seq[Layer] {
+	return func(yield func(Layer) bool) {
+		Walk(err, yield)
+	}
+}
@@ -0,0 +1,48 @@
+package errors
+
+import (
+	goerr "errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLayers(t *testing.T) {
+
+	t.Run("should range over a mixed chain", func(t *testing.T) {
+		root := NewWithErrorAndFields(goerr.New("root"), fields)
+		err := WithError(fmt.Errorf("context: %w", root), goerr.New("outer"))
+
+		var messages []string
+		var depths []int
+		for layer := range Layers(err) {
+			messages = append(messages, layer.Actual.Error())
+			depths = append(depths, layer.Depth)
+		}
+		assert.Equal(t, []string{"outer", "context: " + root.Error(), "root"}, messages)
+		assert.Equal(t, []int{0, 1, 2}, depths)
+	})
+
+	t.Run("should expose the fields and location of each layer", func(t *testing.T) {
+		for layer := range Layers(getThirdWrap()) {
+			assert.NotEmpty(t, layer.Fields)
+			assert.Contains(t, layer.Location, "github.com/hantonelli/errors/wrappederror_helper_test.go:")
+		}
+	})
+
+	t.Run("should stop when the loop breaks", func(t *testing.T) {
+		count := 0
+		for range Layers(getThirdWrap()) {
+			count++
+			break
+		}
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("should yield nothing for a nil error", func(t *testing.T) {
+		for range Layers(nil) {
+			t.Fatal("expected no layers")
+		}
+	})
+}
@@ -0,0 +1,184 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+type jsonChain struct {
+	Code   Code                       `json:"code,omitempty"`
+	Layers []jsonLayer                `json:"layers"`
+	Fields map[string]json.RawMessage `json:"fields"`
+}
+
+type jsonLayer struct {
+	Message        string                     `json:"message"`
+	Template       string                     `json:"template,omitempty"`
+	MessageKey     string                     `json:"message_key,omitempty"`
+	Code           Code                       `json:"code,omitempty"`
+	Location       *jsonLocation              `json:"location,omitempty"`
+	Fields         map[string]json.RawMessage `json:"fields,omitempty"`
+	Stack          []jsonFrame                `json:"stack,omitempty"`
+	StackTruncated bool                       `json:"stack_truncated,omitempty"`
+	Errors         []json.RawMessage          `json:"errors,omitempty"`
+}
+
+type jsonFrame struct {
+	Function string `json:"function"`
+	Package  string `json:"package"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+type jsonLocation struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// MarshalJSON returns the chain as an ordered array of layers, from the outermost to the root, and the
+// merged fields of all of them. Field values that cannot be encoded are written in their %v form, or as
+// their type name when they are cyclic.
+func (e *WrappedErrorImpl) MarshalJSON() ([]byte, error) {
+	chain := jsonChain{Layers: []jsonLayer{}}
+	var err error = e
+	for err != nil {
+		we, isWrappedError := err.(WrappedError)
+		if !isWrappedError {
+			layer := jsonLayer{Message: err.Error()}
+			if agg, ok := err.(*AggregateError); ok {
+				layer.Errors = jsonErrors(agg.errs)
+			}
+			chain.Layers = append(chain.Layers, layer)
+			break
+		}
+		layer := jsonLayer{
+			Message: we.GetActual().Error(),
+			Code:    errorCode(we),
+			Fields:  jsonFields(we.GetFields()),
+		}
+		if t, ok := we.GetActual().(*TemplateMessage); ok {
+			layer.Template = t.Template()
+		}
+		if impl, ok := we.(*WrappedErrorImpl); ok {
+			loc := impl.location()
+			layer.Location = &jsonLocation{File: loc.file, Line: loc.line}
+			layer.MessageKey = impl.messageKey
+		}
+		if we.GetPrevious() == nil {
+			for _, frame := range we.GetStackFrames() {
+				layer.Stack = append(layer.Stack, jsonFrame(frame))
+			}
+			if impl, ok := we.(*WrappedErrorImpl); ok {
+				layer.StackTruncated = impl.stackTruncated
+			}
+		}
+		chain.Layers = append(chain.Layers, layer)
+		err = we.GetPrevious()
+	}
+	chain.Code = CodeOf(e)
+	chain.Fields = jsonFields(e.GetAllFields())
+	return json.Marshal(chain)
+}
+
+// jsonFields encodes every field value on its own, so a value that cannot be encoded only degrades
+// that value to its SprintValue form instead of failing the whole chain.
+func jsonFields(fields map[string]interface{}) map[string]json.RawMessage {
+	encoded := make(map[string]json.RawMessage, len(fields))
+	for k, v := range fields {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			raw, _ = json.Marshal(SprintValue(v))
+		}
+		encoded[k] = raw
+	}
+	return encoded
+}
+
+// SprintValue returns the %v form of v, or its type name when v contains itself, as %v never returns
+// on such values.
+func SprintValue(v interface{}) string {
+	if isCyclic(reflect.ValueOf(v), map[uintptr]bool{}, 0) {
+		return fmt.Sprintf("%T", v)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// isCyclic reports whether printing v with %v would go through the same map or slice again. Like fmt,
+// it only follows a pointer at the top level and stops at values with an Error or String method.
+func isCyclic(v reflect.Value, path map[uintptr]bool, depth int) bool {
+	if !v.IsValid() {
+		return false
+	}
+	if v.CanInterface() {
+		switch v.Interface().(type) {
+		case error, fmt.Stringer:
+			return false
+		}
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		return isCyclic(v.Elem(), path, depth)
+	case reflect.Pointer:
+		if depth > 0 || v.IsNil() {
+			return false
+		}
+		return isCyclic(v.Elem(), path, depth+1)
+	case reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return false
+		}
+		p := v.Pointer()
+		if path[p] {
+			return true
+		}
+		path[p] = true
+		defer delete(path, p)
+		if v.Kind() == reflect.Map {
+			iter := v.MapRange()
+			for iter.Next() {
+				if isCyclic(iter.Key(), path, depth+1) || isCyclic(iter.Value(), path, depth+1) {
+					return true
+				}
+			}
+			return false
+		}
+		for i := 0; i < v.Len(); i++ {
+			if isCyclic(v.Index(i), path, depth+1) {
+				return true
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if isCyclic(v.Index(i), path, depth+1) {
+				return true
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if isCyclic(v.Field(i), path, depth+1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jsonErrors encodes every error on its own, using its MarshalJSON method when it has one.
+func jsonErrors(errs []error) []json.RawMessage {
+	encoded := make([]json.RawMessage, 0, len(errs))
+	for _, err := range errs {
+		if m, ok := err.(json.Marshaler); ok {
+			if raw, jsonErr := m.MarshalJSON(); jsonErr == nil {
+				encoded = append(encoded, raw)
+				continue
+			}
+		}
+		raw, _ := json.Marshal(jsonChain{
+			Layers: []jsonLayer{{Message: err.Error()}},
+			Fields: map[string]json.RawMessage{},
+		})
+		encoded = append(encoded, raw)
+	}
+	return encoded
+}
@@ -0,0 +1,110 @@
+package errors
+
+import (
+	"encoding/json"
+	goerr "errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type cyclicValue struct {
+	Next *cyclicValue
+}
+
+func TestMarshalJSON(t *testing.T) {
+
+	t.Run("should marshal every layer and the merged fields", func(t *testing.T) {
+		err := getThirdWrap()
+		b, jsonErr := json.Marshal(err)
+		assert.NoError(t, jsonErr)
+
+		var chain struct {
+			Layers []struct {
+				Message  string `json:"message"`
+				Location struct {
+					File string `json:"file"`
+					Line int    `json:"line"`
+				} `json:"location"`
+				Fields map[string]interface{} `json:"fields"`
+				Stack  []Frame                `json:"stack"`
+			} `json:"layers"`
+			Fields map[string]interface{} `json:"fields"`
+		}
+		assert.NoError(t, json.Unmarshal(b, &chain))
+
+		assert.Len(t, chain.Layers, 3)
+		assert.Equal(t, "third wrap", chain.Layers[0].Message)
+		assert.Equal(t, "github.com/hantonelli/errors/wrappederror_helper_test.go", chain.Layers[0].Location.File)
+		assert.Equal(t, 18, chain.Layers[0].Location.Line)
+		assert.Equal(t, map[string]interface{}{"third-wrap-string": "test-string", "third-wrap-number": float64(123)}, chain.Layers[0].Fields)
+		assert.Empty(t, chain.Layers[0].Stack)
+		assert.Equal(t, "second wrap", chain.Layers[1].Message)
+		assert.Equal(t, "previous", chain.Layers[2].Message)
+		assert.Equal(t, 35, chain.Layers[2].Location.Line)
+		assert.Equal(t, Frame{
+			Function: "getFirstWrapped",
+			Package:  "github.com/hantonelli/errors",
+			File:     "github.com/hantonelli/errors/wrappederror_helper_test.go",
+			Line:     35,
+		}, chain.Layers[2].Stack[0])
+		assert.Len(t, chain.Fields, 6)
+		assert.Equal(t, "test-string", chain.Fields["first-wrap-string"])
+	})
+
+	t.Run("should marshal a previous error that is not wrapped", func(t *testing.T) {
+		err := WithError(goerr.New("previous"), goerr.New("actual"))
+		b, jsonErr := json.Marshal(err)
+		assert.NoError(t, jsonErr)
+		assert.Contains(t, string(b), `{"message":"previous"}`)
+		assert.Contains(t, string(b), `"fields":{}`)
+	})
+
+	t.Run("should degrade values that cannot be encoded", func(t *testing.T) {
+		cyclic := &cyclicValue{}
+		cyclic.Next = cyclic
+		cyclicMap := map[string]interface{}{}
+		cyclicMap["self"] = cyclicMap
+		err := NewWithMsgAndFields("actual", map[string]interface{}{
+			"func":       func() {},
+			"channel":    make(chan int),
+			"cyclic":     cyclic,
+			"cyclic_map": cyclicMap,
+			"ratio":      math.NaN(),
+			"number":     1,
+		})
+		b, jsonErr := json.Marshal(err)
+		assert.NoError(t, jsonErr)
+
+		var chain struct {
+			Fields map[string]interface{} `json:"fields"`
+		}
+		assert.NoError(t, json.Unmarshal(b, &chain))
+		assert.Regexp(t, "^0x[0-9a-f]+$", chain.Fields["func"])
+		assert.Regexp(t, "^0x[0-9a-f]+$", chain.Fields["channel"])
+		assert.Regexp(t, `^&\{0x[0-9a-f]+\}$`, chain.Fields["cyclic"])
+		assert.Equal(t, "map[string]interface {}", chain.Fields["cyclic_map"])
+		assert.Equal(t, "NaN", chain.Fields["ratio"])
+		assert.Equal(t, float64(1), chain.Fields["number"])
+	})
+}
+
+func TestSprintValue(t *testing.T) {
+	t.Run("should print values in their %v form", func(t *testing.T) {
+		shared := []int{1}
+		assert.Equal(t, "NaN", SprintValue(math.NaN()))
+		assert.Equal(t, "map[a:[1] b:[1]]", SprintValue(map[string][]int{"a": shared, "b": shared}))
+		assert.Equal(t, "<nil>", SprintValue(nil))
+	})
+
+	t.Run("should print cyclic values as their type name", func(t *testing.T) {
+		cyclicMap := map[string]interface{}{}
+		cyclicMap["self"] = cyclicMap
+		assert.Equal(t, "map[string]interface {}", SprintValue(cyclicMap))
+
+		cyclicSlice := []interface{}{nil}
+		cyclicSlice[0] = cyclicSlice
+		assert.Equal(t, "struct { S []interface {} }", SprintValue(struct{ S []interface{} }{cyclicSlice}))
+	})
+}
@@ -0,0 +1,22 @@
+package errors
+
+// NewWithKey returns a new WrappedErrorImpl with the provided message, used when the key has no
+// translation, the message key and the fields, which are the parameters of the translated message.
+func NewWithKey(key, message string, fields map[string]interface{}) *WrappedErrorImpl {
+	return Msg(message).MessageKey(key).Fields(fields).build()
+}
+
+// GetMessageKey returns the message key of this layer, which is empty when it has none.
+func (e WrappedErrorImpl) GetMessageKey() string {
+	return e.messageKey
+}
+
+// WithMessageKey returns a copy of the error with the provided message key on its layer.
+func (e *WrappedErrorImpl) WithMessageKey(key string) *WrappedErrorImpl {
+	if e == nil {
+		return nil
+	}
+	withKey := *e
+	withKey.messageKey = key
+	return &withKey
+}
@@ -0,0 +1,33 @@
+package errors
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageKey(t *testing.T) {
+	t.Run("should keep the message key apart from the message", func(t *testing.T) {
+		err := NewWithKey("user.not_found", "user not found", map[string]interface{}{"user_id": 42})
+		assert.Equal(t, "user.not_found", err.GetMessageKey())
+		assert.Equal(t, "user not found", err.GetActual().Error())
+		assert.Equal(t, map[string]interface{}{"user_id": 42}, err.GetFields())
+		assert.Equal(t, "Message: user not found. Location: github.com/hantonelli/errors/messagekey_test.go:12. "+
+			"Fields: map[user_id:42].", err.Error())
+	})
+
+	t.Run("should set the message key on a copy", func(t *testing.T) {
+		err := Msg("user not found").Err()
+		withKey := err.WithMessageKey("user.not_found")
+		assert.Equal(t, "", err.GetMessageKey())
+		assert.Equal(t, "user.not_found", withKey.GetMessageKey())
+		assert.Nil(t, (*WrappedErrorImpl)(nil).WithMessageKey("key"))
+	})
+
+	t.Run("should add the message key to json", func(t *testing.T) {
+		b, err := json.Marshal(Msg("user not found").MessageKey("user.not_found").Err())
+		assert.NoError(t, err)
+		assert.Contains(t, string(b), `"message_key":"user.not_found"`)
+	})
+}
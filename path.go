@@ -0,0 +1,174 @@
+package errors
+
+import (
+	"bufio"
+	"go/build"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// PathPolicy specifies how source file paths are trimmed in locations and stack frames.
+type PathPolicy int32
+
+const (
+	// PathModuleRelative trims paths to the module path followed by the file path inside the module,
+	// for example github.com/hantonelli/errors/wrappederror.go. Files of the standard library are
+	// trimmed to their package path, and paths that cannot be resolved are kept as they are.
+	PathModuleRelative PathPolicy = iota
+	// PathFull keeps paths as they were recorded by the compiler.
+	PathFull
+	// PathBaseName trims paths to the file name.
+	PathBaseName
+)
+
+var (
+	pathPolicy int32
+
+	gopaths    []string
+	goroot     string
+	gomodcache string
+	mainModule string
+
+	// modulePaths caches the module path found for each directory, or "" when there is none.
+	modulePaths sync.Map
+	// moduleRelativePaths caches the module relative path of each file.
+	moduleRelativePaths sync.Map
+)
+
+func init() {
+	gopath := os.Getenv("GOPATH")
+	if gopath == "" {
+		gopath = build.Default.GOPATH
+	}
+	for _, p := range filepath.SplitList(gopath) {
+		gopaths = append(gopaths, filepath.ToSlash(p)+"/src/")
+	}
+	goroot = os.Getenv("GOROOT")
+	if goroot == "" {
+		goroot = build.Default.GOROOT
+	}
+	goroot = filepath.ToSlash(goroot) + "/src/"
+	gomodcache = os.Getenv("GOMODCACHE")
+	if gomodcache == "" && len(gopaths) > 0 {
+		gomodcache = filepath.Join(filepath.SplitList(gopath)[0], "pkg", "mod")
+	}
+	gomodcache = filepath.ToSlash(gomodcache) + "/"
+	if info, ok := debug.ReadBuildInfo(); ok {
+		mainModule = info.Main.Path
+	}
+}
+
+// SetPathPolicy sets how source file paths are trimmed from then on.
+func SetPathPolicy(policy PathPolicy) {
+	atomic.StoreInt32(&pathPolicy, int32(policy))
+}
+
+func cleanFilePath(file string) string {
+	switch PathPolicy(atomic.LoadInt32(&pathPolicy)) {
+	case PathFull:
+		return file
+	case PathBaseName:
+		return path.Base(file)
+	default:
+		if cached, ok := moduleRelativePaths.Load(file); ok {
+			return cached.(string)
+		}
+		trimmed := moduleRelativePath(file)
+		moduleRelativePaths.Store(file, trimmed)
+		return trimmed
+	}
+}
+
+// moduleRelativePath trims a file path recorded by the compiler to its module relative form. It handles
+// -trimpath builds, the module cache, GOROOT, modules checked out locally and GOPATH, in that order.
+func moduleRelativePath(file string) string {
+	if !path.IsAbs(file) && !filepath.IsAbs(file) {
+		return stripModuleVersion(file)
+	}
+	if strings.HasPrefix(file, gomodcache) {
+		return stripModuleVersion(unescapeModulePath(strings.TrimPrefix(file, gomodcache)))
+	}
+	if strings.HasPrefix(file, goroot) {
+		return strings.TrimPrefix(file, goroot)
+	}
+	for dir := path.Dir(file); ; dir = path.Dir(dir) {
+		if modulePath := readModulePath(dir); modulePath != "" {
+			return modulePath + strings.TrimPrefix(file, dir)
+		}
+		if path.Dir(dir) == dir {
+			break
+		}
+	}
+	for _, src := range gopaths {
+		if strings.HasPrefix(file, src) {
+			return strings.TrimPrefix(file, src)
+		}
+	}
+	if mainModule != "" {
+		if i := strings.Index(file, "/"+mainModule+"/"); i >= 0 {
+			return file[i+1:]
+		}
+	}
+	return file
+}
+
+// readModulePath returns the module path declared in the go.mod file of dir, or "" if there is none.
+func readModulePath(dir string) string {
+	if cached, ok := modulePaths.Load(dir); ok {
+		return cached.(string)
+	}
+	var modulePath string
+	if f, err := os.Open(filepath.Join(filepath.FromSlash(dir), "go.mod")); err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if strings.HasPrefix(line, "module") {
+				line = strings.TrimSpace(strings.TrimPrefix(line, "module"))
+				if i := strings.Index(line, "//"); i >= 0 {
+					line = strings.TrimSpace(line[:i])
+				}
+				modulePath = strings.Trim(line, "\"`")
+				break
+			}
+		}
+		f.Close()
+	}
+	modulePaths.Store(dir, modulePath)
+	return modulePath
+}
+
+// stripModuleVersion removes the @version suffix of the module path, as found in the module cache and
+// in -trimpath builds, for example github.com/a/b@v1.2.3/c.go becomes github.com/a/b/c.go.
+func stripModuleVersion(file string) string {
+	at := strings.Index(file, "@")
+	if at < 0 {
+		return file
+	}
+	end := strings.Index(file[at:], "/")
+	if end < 0 {
+		return file[:at]
+	}
+	return file[:at] + file[at+end:]
+}
+
+// unescapeModulePath reverts the module cache escaping of upper case letters, where !a stands for A.
+func unescapeModulePath(file string) string {
+	if !strings.Contains(file, "!") {
+		return file
+	}
+	var b strings.Builder
+	for i := 0; i < len(file); i++ {
+		if file[i] == '!' && i+1 < len(file) {
+			i++
+			b.WriteString(strings.ToUpper(file[i : i+1]))
+			continue
+		}
+		b.WriteByte(file[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,61 @@
+package errors
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModuleRelativePath(t *testing.T) {
+
+	t.Run("should strip the version of -trimpath paths", func(t *testing.T) {
+		assert.Equal(t, "github.com/a/b/c/d.go", moduleRelativePath("github.com/a/b@v1.2.3/c/d.go"))
+		assert.Equal(t, "github.com/a/b/d.go", moduleRelativePath("github.com/a/b/d.go"))
+	})
+
+	t.Run("should trim the module cache", func(t *testing.T) {
+		file := gomodcache + "github.com/!azure/sdk@v0.1.0-pre/a/b.go"
+		assert.Equal(t, "github.com/Azure/sdk/a/b.go", moduleRelativePath(file))
+	})
+
+	t.Run("should trim GOROOT", func(t *testing.T) {
+		assert.Equal(t, "net/http/server.go", moduleRelativePath(goroot+"net/http/server.go"))
+	})
+
+	t.Run("should use the go.mod of a local module", func(t *testing.T) {
+		dir := filepath.ToSlash(t.TempDir())
+		err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("// comment\nmodule \"example.com/m\" // main\n"), 0o600)
+		assert.NoError(t, err)
+		assert.Equal(t, "example.com/m/sub/x.go", moduleRelativePath(dir+"/sub/x.go"))
+	})
+
+	t.Run("should keep paths that cannot be resolved", func(t *testing.T) {
+		assert.Equal(t, "/nowhere/x.go", moduleRelativePath("/nowhere/x.go"))
+	})
+}
+
+func TestSetPathPolicy(t *testing.T) {
+	defer SetPathPolicy(PathModuleRelative)
+
+	t.Run("should keep the full path", func(t *testing.T) {
+		SetPathPolicy(PathFull)
+		err := NewWithMsg("actual")
+		assert.Equal(t, symbolize(err.pc).File, err.location().file)
+		assert.Equal(t, "path_test.go", filepath.Base(err.location().file))
+	})
+
+	t.Run("should keep the base name", func(t *testing.T) {
+		SetPathPolicy(PathBaseName)
+		err := NewWithMsg("actual")
+		assert.Equal(t, "path_test.go", err.location().file)
+		assert.Equal(t, "path_test.go", err.GetStackFrames()[0].File)
+	})
+
+	t.Run("should trim to the module relative path", func(t *testing.T) {
+		SetPathPolicy(PathModuleRelative)
+		err := NewWithMsg("actual")
+		assert.Equal(t, "github.com/hantonelli/errors/path_test.go", err.location().file)
+	})
+}
@@ -0,0 +1,94 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+)
+
+// PanicValueField is the field where Recover keeps a panic value that is not an error.
+const PanicValueField = "panic_value"
+
+// panicFramesMargin is the number of extra frames captured to make room for the frames of the recover
+// site and of the runtime, which are dropped.
+const panicFramesMargin = 16
+
+// Recover turns a panic into a WrappedErrorImpl stored in errp. It must be deferred directly, usually
+// with a named error result:
+//
+//	func handle() (err error) {
+//		defer errors.Recover(&err)
+//		...
+//	}
+//
+// The error is located where the panic happened and has the stack of the panicking goroutine. A panic
+// value that is an error becomes the actual error, any other value is kept in the PanicValueField field.
+// When errp is nil there is nowhere to store the error, so the panic goes on.
+func Recover(errp *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if errp == nil {
+		panic(r)
+	}
+	*errp = fromPanic(r)
+}
+
+// Go runs fn in a new goroutine, turning a panic into an error as Recover does, and sends its result
+// on the returned channel, which is closed afterwards.
+func Go(fn func() error) <-chan error {
+	result := make(chan error, 1)
+	go func() {
+		defer close(result)
+		result <- runRecovered(fn)
+	}()
+	return result
+}
+
+func runRecovered(fn func() error) (err error) {
+	defer Recover(&err)
+	return fn()
+}
+
+func fromPanic(r interface{}) *WrappedErrorImpl {
+	e := &WrappedErrorImpl{fields: map[string]interface{}{}}
+	if err, ok := r.(error); ok {
+		e.actual = err
+	} else {
+		e.actual = errors.New(fmt.Sprintf("panic: %v", r))
+		e.fields[PanicValueField] = r
+	}
+	e.stack, e.stackTruncated = capturePanicStack()
+	if len(e.stack) > 0 {
+		e.pc = e.stack[0]
+	}
+	return e
+}
+
+// capturePanicStack returns the stack of the panicking goroutine from the frame that panicked, dropping
+// the frames of the deferred call and of the runtime panic machinery.
+func capturePanicStack() ([]uintptr, bool) {
+	depth := int(atomic.LoadInt64(&maxStackDepth))
+	if depth == 0 {
+		return nil, false
+	}
+	pcs := make([]uintptr, depth+panicFramesMargin)
+	pcs = pcs[:runtime.Callers(1, pcs)]
+	start := 0
+	for i, pc := range pcs {
+		if frame := symbolize(pc); frame.Package == "runtime" && frame.Function == "gopanic" {
+			start = i + 1
+			break
+		}
+	}
+	for start < len(pcs) && symbolize(pcs[start]).Package == "runtime" {
+		start++
+	}
+	pcs = pcs[start:]
+	if len(pcs) > depth {
+		return pcs[:depth], true
+	}
+	return pcs, false
+}
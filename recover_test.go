@@ -0,0 +1,104 @@
+package errors
+
+import (
+	goerr "errors"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errPanicked = goerr.New("panicked with an error")
+
+func panicWithValue() (err error) {
+	defer Recover(&err)
+	panic("boom")
+}
+
+func panicWithError() (err error) {
+	defer Recover(&err)
+	panic(errPanicked)
+}
+
+func panicWithNilPointer() (err error) {
+	defer Recover(&err)
+	var m *map[string]int
+	(*m)["key"] = 1
+	return nil
+}
+
+func TestRecover(t *testing.T) {
+
+	t.Run("should keep a value that is not an error as a field", func(t *testing.T) {
+		err := panicWithValue()
+		we, ok := err.(*WrappedErrorImpl)
+		assert.True(t, ok)
+		assert.Equal(t, "panic: boom", we.GetActual().Error())
+		assert.Equal(t, "boom", we.GetFields()[PanicValueField])
+	})
+
+	t.Run("should keep an error as the actual error", func(t *testing.T) {
+		err := panicWithError()
+		assert.True(t, goerr.Is(err, errPanicked))
+		assert.Empty(t, err.(*WrappedErrorImpl).GetFields())
+	})
+
+	t.Run("should capture the panicking stack", func(t *testing.T) {
+		we := panicWithValue().(*WrappedErrorImpl)
+		assert.Equal(t, "github.com/hantonelli/errors/recover_test.go:15", we.location().String())
+		frames := we.GetStackFrames()
+		assert.Equal(t, "panicWithValue", frames[0].Function)
+		assert.Equal(t, "TestRecover.func3", frames[1].Function)
+	})
+
+	t.Run("should capture the stack of a runtime panic", func(t *testing.T) {
+		we := panicWithNilPointer().(*WrappedErrorImpl)
+		var runtimeErr runtime.Error
+		assert.True(t, goerr.As(we, &runtimeErr))
+		assert.Equal(t, "github.com/hantonelli/errors/recover_test.go:26", we.location().String())
+		assert.Equal(t, "panicWithNilPointer", we.GetStackFrames()[0].Function)
+	})
+
+	t.Run("should leave the error untouched when there is no panic", func(t *testing.T) {
+		err := func() (err error) {
+			defer Recover(&err)
+			return errPanicked
+		}()
+		assert.Equal(t, errPanicked, err)
+	})
+
+	t.Run("should panic again when there is nowhere to store the error", func(t *testing.T) {
+		assert.PanicsWithValue(t, "boom", func() {
+			defer Recover(nil)
+			panic("boom")
+		})
+	})
+}
+
+func TestGo(t *testing.T) {
+
+	t.Run("should report the error returned", func(t *testing.T) {
+		result := Go(func() error {
+			return errPanicked
+		})
+		assert.Equal(t, errPanicked, <-result)
+		_, open := <-result
+		assert.False(t, open)
+	})
+
+	t.Run("should report a panic as an error", func(t *testing.T) {
+		err := <-Go(func() error {
+			panic("boom")
+		})
+		we, ok := err.(*WrappedErrorImpl)
+		assert.True(t, ok)
+		assert.Equal(t, "boom", we.GetFields()[PanicValueField])
+		assert.Equal(t, "github.com/hantonelli/errors/recover_test.go:91", we.location().String())
+	})
+
+	t.Run("should report nil when there is no error", func(t *testing.T) {
+		assert.Nil(t, <-Go(func() error {
+			return nil
+		}))
+	})
+}
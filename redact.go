@@ -0,0 +1,154 @@
+package errors
+
+import (
+	"encoding/json"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Sensitive wraps a field value that must never be rendered, whatever its key is. Value keeps the
+// original value for local debugging.
+type Sensitive struct {
+	Value interface{}
+}
+
+// String returns the replacement of the redaction policy instead of the value.
+func (s Sensitive) String() string {
+	return currentRedactionPolicy().replacement()
+}
+
+// GoString returns the replacement of the redaction policy instead of the value.
+func (s Sensitive) GoString() string {
+	return s.String()
+}
+
+// MarshalJSON returns the replacement of the redaction policy instead of the value.
+func (s Sensitive) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// LogValue returns the replacement of the redaction policy instead of the value.
+func (s Sensitive) LogValue() slog.Value {
+	return slog.StringValue(s.String())
+}
+
+// RedactionPolicy specifies which field values are replaced in Error(), GetFields, GetAllFields and
+// every rendering built on them. Values wrapped in Sensitive are always replaced.
+type RedactionPolicy struct {
+	// Keys are field names whose values are replaced, compared case insensitively.
+	Keys []string
+	// Patterns are matched against field names whose values are replaced. Anchor them, as `token`
+	// alone also matches names such as max_tokens.
+	Patterns []*regexp.Regexp
+	// Replacement is written instead of the value. It is "[REDACTED]" when empty.
+	Replacement string
+}
+
+// DefaultRedactionPolicy is the redaction policy used until SetRedactionPolicy is called.
+var DefaultRedactionPolicy = RedactionPolicy{
+	Keys:     []string{"password", "token", "secret", "email", "authorization", "api_key"},
+	Patterns: []*regexp.Regexp{regexp.MustCompile(`(?i)_(password|secret|token)$`)},
+}
+
+// maxCachedVerdicts bounds the number of field names whose verdict is cached for a policy.
+const maxCachedVerdicts = 4096
+
+// redactionState is a redaction policy with its keys in lower case and the verdicts already computed
+// for field names, so fields are not matched against the policy on every rendering.
+type redactionState struct {
+	policy   RedactionPolicy
+	keys     map[string]bool
+	verdicts sync.Map
+	cached   int64
+}
+
+var redactionPolicy atomic.Pointer[redactionState]
+
+func init() {
+	SetRedactionPolicy(DefaultRedactionPolicy)
+}
+
+// SetRedactionPolicy sets the redaction policy applied from then on.
+func SetRedactionPolicy(policy RedactionPolicy) {
+	state := &redactionState{policy: policy, keys: make(map[string]bool, len(policy.Keys))}
+	for _, k := range policy.Keys {
+		state.keys[strings.ToLower(k)] = true
+	}
+	redactionPolicy.Store(state)
+}
+
+func currentRedactionPolicy() *RedactionPolicy {
+	return &redactionPolicy.Load().policy
+}
+
+func (s *redactionState) isSensitive(key string) bool {
+	if verdict, ok := s.verdicts.Load(key); ok {
+		return verdict.(bool)
+	}
+	verdict := s.keys[strings.ToLower(key)]
+	for _, pattern := range s.policy.Patterns {
+		verdict = verdict || pattern.MatchString(key)
+	}
+	if atomic.AddInt64(&s.cached, 1) <= maxCachedVerdicts {
+		s.verdicts.Store(key, verdict)
+	}
+	return verdict
+}
+
+// IsSensitive reports whether the value of the field key is replaced by the policy.
+func (p RedactionPolicy) IsSensitive(key string) bool {
+	for _, k := range p.Keys {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	for _, pattern := range p.Patterns {
+		if pattern.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact returns a copy of fields with the values of sensitive fields replaced.
+func (p RedactionPolicy) Redact(fields map[string]interface{}) map[string]interface{} {
+	return redact(fields, p.IsSensitive, p.replacement())
+}
+
+func redact(fields map[string]interface{}, isSensitive func(string) bool, replacement string) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		if _, sensitive := v.(Sensitive); sensitive || isSensitive(k) {
+			redacted[k] = replacement
+			continue
+		}
+		redacted[k] = v
+	}
+	return redacted
+}
+
+func (p RedactionPolicy) replacement() string {
+	if p.Replacement == "" {
+		return "[REDACTED]"
+	}
+	return p.Replacement
+}
+
+// redactValue returns the replacement of the current redaction policy when the field is sensitive, or
+// the value as it is.
+func redactValue(key string, value interface{}) interface{} {
+	state := redactionPolicy.Load()
+	if _, sensitive := value.(Sensitive); sensitive || state.isSensitive(key) {
+		return state.policy.replacement()
+	}
+	return value
+}
+
+// redactFields redacts fields with the current redaction policy.
+func redactFields(fields map[string]interface{}) map[string]interface{} {
+	state := redactionPolicy.Load()
+	return redact(fields, state.isSensitive, state.policy.replacement())
+}
@@ -0,0 +1,97 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	goerr "errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedaction(t *testing.T) {
+	sensitiveFields := map[string]interface{}{
+		"password": "hunter2",
+		"Email":    "user@example.com",
+		"db_token": "abc",
+		"card":     Sensitive{Value: "4111"},
+		"user_id":  42,
+	}
+	redacted := map[string]interface{}{
+		"password": "[REDACTED]",
+		"Email":    "[REDACTED]",
+		"db_token": "[REDACTED]",
+		"card":     "[REDACTED]",
+		"user_id":  42,
+	}
+
+	t.Run("should redact the fields in Error", func(t *testing.T) {
+		err := NewWithMsgAndFields("login failed", sensitiveFields)
+		assert.Contains(t, err.Error(), "Fields: map[Email:[REDACTED] card:[REDACTED] db_token:[REDACTED] password:[REDACTED] user_id:42].")
+		assert.NotContains(t, fmt.Sprintf("%+v", err), "hunter2")
+	})
+
+	t.Run("should redact the fields and keep the originals available", func(t *testing.T) {
+		err := WithErrorAndFields(NewWithMsgAndFields("login failed", sensitiveFields), goerr.New("outer"), nil)
+		assert.Equal(t, redacted, err.GetAllFields())
+		assert.Equal(t, sensitiveFields, err.GetAllFieldsUnredacted())
+
+		inner := err.GetPrevious().(*WrappedErrorImpl)
+		assert.Equal(t, redacted, inner.GetFields())
+		assert.Equal(t, sensitiveFields, inner.GetFieldsUnredacted())
+	})
+
+	t.Run("should redact the fields in JSON and slog", func(t *testing.T) {
+		err := NewWithMsgAndFields("login failed", sensitiveFields)
+		b, jsonErr := json.Marshal(err)
+		assert.NoError(t, jsonErr)
+		assert.NotContains(t, string(b), "hunter2")
+		assert.NotContains(t, string(b), "4111")
+
+		var logs bytes.Buffer
+		slog.New(NewSlogHandler(slog.NewJSONHandler(&logs, nil))).Error("failed", "err", err)
+		assert.NotContains(t, logs.String(), "hunter2")
+		assert.NotContains(t, logs.String(), "user@example.com")
+		assert.Contains(t, logs.String(), `"user_id":42`)
+	})
+
+	t.Run("should redact the fields of an aggregate", func(t *testing.T) {
+		agg := Aggregate(NewWithMsgAndFields("login failed", sensitiveFields))
+		assert.Equal(t, redacted, agg.GetAllFields())
+		assert.Equal(t, sensitiveFields, agg.GetAllFieldsUnredacted())
+	})
+
+	t.Run("should not redact names that only contain a sensitive word", func(t *testing.T) {
+		err := NewWithMsgAndFields("completion failed", map[string]interface{}{"max_tokens": 100, "token_count": 5, "api_token": "abc"})
+		assert.Equal(t, map[string]interface{}{"max_tokens": 100, "token_count": 5, "api_token": "[REDACTED]"}, err.GetFields())
+	})
+
+	t.Run("should never print a Sensitive value", func(t *testing.T) {
+		s := Sensitive{Value: "4111"}
+		assert.Equal(t, "[REDACTED]", fmt.Sprintf("%v", s))
+		assert.Equal(t, "[REDACTED]", fmt.Sprintf("%#v", s))
+		b, _ := json.Marshal(map[string]interface{}{"card": s})
+		assert.Equal(t, `{"card":"[REDACTED]"}`, string(b))
+	})
+
+	t.Run("should use the policy set", func(t *testing.T) {
+		SetRedactionPolicy(RedactionPolicy{
+			Keys:        []string{"user_id"},
+			Patterns:    []*regexp.Regexp{regexp.MustCompile(`^db_`)},
+			Replacement: "***",
+		})
+		defer SetRedactionPolicy(DefaultRedactionPolicy)
+
+		err := NewWithMsgAndFields("login failed", sensitiveFields)
+		assert.Equal(t, map[string]interface{}{
+			"password": "hunter2",
+			"Email":    "user@example.com",
+			"db_token": "***",
+			"card":     "***",
+			"user_id":  "***",
+		}, err.GetFields())
+	})
+}
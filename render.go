@@ -0,0 +1,279 @@
+package errors
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Renderer renders a chain as the string returned by Error().
+type Renderer interface {
+	Render(err error) string
+}
+
+// RendererFunc is an adapter to use a function as a Renderer.
+type RendererFunc func(err error) string
+
+// Render calls f(err).
+func (f RendererFunc) Render(err error) string {
+	return f(err)
+}
+
+var renderer atomic.Value
+
+func init() {
+	renderer.Store(rendererHolder{LegacyRenderer{}})
+}
+
+// rendererHolder keeps the stored value of the same concrete type, as atomic.Value requires.
+type rendererHolder struct {
+	Renderer
+}
+
+// SetRenderer sets the renderer used by Error() from then on. A nil renderer restores the default
+// LegacyRenderer.
+func SetRenderer(r Renderer) {
+	if r == nil {
+		r = LegacyRenderer{}
+	}
+	renderer.Store(rendererHolder{r})
+}
+
+func currentRenderer() Renderer {
+	return renderer.Load().(rendererHolder).Renderer
+}
+
+// renderedLayer is a layer of a chain as the renderers see it. A layer of an aggregate has the
+// rendered layers of each aggregated error instead of a message.
+type renderedLayer struct {
+	message  string
+	code     Code
+	location string
+	fields   map[string]interface{}
+	errs     [][]renderedLayer
+}
+
+// renderedLayers returns the layers of the chain from the outermost one, following the previous
+// errors of the wrapped layers.
+func renderedLayers(err error) []renderedLayer {
+	var layers []renderedLayer
+	for err != nil {
+		switch x := err.(type) {
+		case *AggregateError:
+			layer := renderedLayer{}
+			for _, child := range x.errs {
+				layer.errs = append(layer.errs, renderedLayers(child))
+			}
+			return append(layers, layer)
+		case WrappedError:
+			layer := renderedLayer{message: x.GetActual().Error(), code: errorCode(x), fields: x.GetFields()}
+			if impl, ok := x.(*WrappedErrorImpl); ok {
+				layer.location = impl.location().String()
+			}
+			layers = append(layers, layer)
+			err = x.GetPrevious()
+		default:
+			return append(layers, renderedLayer{message: err.Error()})
+		}
+	}
+	return layers
+}
+
+func sortedKeys(fields map[string]interface{}) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// LegacyRenderer renders every layer as "Message: ... Location: ... Fields: map[...]." joined with
+// " <br> ". It is the default renderer.
+type LegacyRenderer struct{}
+
+// Render renders the chain in the legacy format.
+func (r LegacyRenderer) Render(err error) string {
+	switch x := err.(type) {
+	case *WrappedErrorImpl:
+		if x.previous != nil {
+			if _, ok := x.previous.(WrappedError); ok {
+				return fmt.Sprintf("%s <br> %s", printActual(x), r.Render(x.previous))
+			}
+			return fmt.Sprintf("%s <br> Message: %s.", printActual(x), r.Render(x.previous))
+		}
+		return printActual(x)
+	case *AggregateError:
+		var b strings.Builder
+		fmt.Fprintf(&b, "Errors: %d.", len(x.errs))
+		for i, child := range x.errs {
+			fmt.Fprintf(&b, " <br> [%d] %s", i+1, r.Render(child))
+		}
+		return b.String()
+	}
+	return err.Error()
+}
+
+// CompactRenderer renders the chain in a single line, as "message [location code=... key=value]: cause".
+type CompactRenderer struct{}
+
+// Render renders the chain in a single line.
+func (CompactRenderer) Render(err error) string {
+	var b strings.Builder
+	writeCompact(&b, renderedLayers(err))
+	return b.String()
+}
+
+func writeCompact(b *strings.Builder, layers []renderedLayer) {
+	for i, layer := range layers {
+		if i > 0 {
+			b.WriteString(": ")
+		}
+		if layer.errs != nil {
+			fmt.Fprintf(b, "%d errors", len(layer.errs))
+			for j, child := range layer.errs {
+				sep := "; "
+				if j == 0 {
+					sep = ": "
+				}
+				fmt.Fprintf(b, "%s[%d] ", sep, j+1)
+				writeCompact(b, child)
+			}
+			continue
+		}
+		b.WriteString(layer.message)
+		var details []string
+		if layer.location != "" {
+			details = append(details, layer.location)
+		}
+		if layer.code != "" {
+			details = append(details, fmt.Sprintf("code=%s", layer.code))
+		}
+		for _, k := range sortedKeys(layer.fields) {
+			details = append(details, fmt.Sprintf("%s=%v", k, layer.fields[k]))
+		}
+		if len(details) > 0 {
+			fmt.Fprintf(b, " [%s]", strings.Join(details, " "))
+		}
+	}
+}
+
+// MultilineRenderer renders every layer in its own line, with its code, location and fields in
+// indented lines below it.
+type MultilineRenderer struct{}
+
+// Render renders the chain in several indented lines.
+func (MultilineRenderer) Render(err error) string {
+	var b strings.Builder
+	writeMultiline(&b, renderedLayers(err), "")
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func writeMultiline(b *strings.Builder, layers []renderedLayer, indent string) {
+	for i, layer := range layers {
+		prefix := ""
+		if i > 0 {
+			prefix = "caused by: "
+		}
+		if layer.errs != nil {
+			fmt.Fprintf(b, "%s%s%d errors:\n", indent, prefix, len(layer.errs))
+			for j, child := range layer.errs {
+				fmt.Fprintf(b, "%s    [%d]\n", indent, j+1)
+				writeMultiline(b, child, indent+"        ")
+			}
+			continue
+		}
+		fmt.Fprintf(b, "%s%s%s\n", indent, prefix, layer.message)
+		if layer.code != "" {
+			fmt.Fprintf(b, "%s    code: %s\n", indent, layer.code)
+		}
+		if layer.location != "" {
+			fmt.Fprintf(b, "%s    location: %s\n", indent, layer.location)
+		}
+		for _, k := range sortedKeys(layer.fields) {
+			fmt.Fprintf(b, "%s    %s: %v\n", indent, k, layer.fields[k])
+		}
+	}
+}
+
+// LogfmtRenderer renders the chain as logfmt key=value pairs. The keys of the first layer are msg,
+// code, location and its fields, those of the following layers are prefixed with cause1., cause2. and
+// so on, and those of aggregated errors with err1., err2. and so on.
+type LogfmtRenderer struct{}
+
+// Render renders the chain as logfmt.
+func (LogfmtRenderer) Render(err error) string {
+	var pairs []string
+	appendLogfmt(&pairs, renderedLayers(err), "")
+	return strings.Join(pairs, " ")
+}
+
+func appendLogfmt(pairs *[]string, layers []renderedLayer, prefix string) {
+	for i, layer := range layers {
+		layerPrefix := prefix
+		if i > 0 {
+			layerPrefix = fmt.Sprintf("%scause%d.", prefix, i)
+		}
+		if layer.errs != nil {
+			*pairs = append(*pairs, logfmtPair(layerPrefix+"errors", len(layer.errs)))
+			for j, child := range layer.errs {
+				appendLogfmt(pairs, child, fmt.Sprintf("%serr%d.", layerPrefix, j+1))
+			}
+			continue
+		}
+		*pairs = append(*pairs, logfmtPair(layerPrefix+"msg", layer.message))
+		if layer.code != "" {
+			*pairs = append(*pairs, logfmtPair(layerPrefix+"code", layer.code))
+		}
+		if layer.location != "" {
+			*pairs = append(*pairs, logfmtPair(layerPrefix+"location", layer.location))
+		}
+		for _, k := range sortedKeys(layer.fields) {
+			*pairs = append(*pairs, logfmtPair(layerPrefix+k, layer.fields[k]))
+		}
+	}
+}
+
+func logfmtPair(key string, value interface{}) string {
+	s := fmt.Sprint(value)
+	if s == "" || strings.ContainsAny(s, " =\"\t\n") {
+		s = strconv.Quote(s)
+	}
+	return key + "=" + s
+}
+
+// MarkdownRenderer renders the chain as a markdown list with one item per layer and its code, location
+// and fields in a nested list.
+type MarkdownRenderer struct{}
+
+// Render renders the chain as markdown.
+func (MarkdownRenderer) Render(err error) string {
+	var b strings.Builder
+	writeMarkdown(&b, renderedLayers(err), "")
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func writeMarkdown(b *strings.Builder, layers []renderedLayer, indent string) {
+	for _, layer := range layers {
+		if layer.errs != nil {
+			fmt.Fprintf(b, "%s- **%d errors**\n", indent, len(layer.errs))
+			for j, child := range layer.errs {
+				fmt.Fprintf(b, "%s  %d. Error %d\n", indent, j+1, j+1)
+				writeMarkdown(b, child, indent+"     ")
+			}
+			continue
+		}
+		fmt.Fprintf(b, "%s- **%s**\n", indent, layer.message)
+		if layer.code != "" {
+			fmt.Fprintf(b, "%s  - Code: `%s`\n", indent, layer.code)
+		}
+		if layer.location != "" {
+			fmt.Fprintf(b, "%s  - Location: `%s`\n", indent, layer.location)
+		}
+		for _, k := range sortedKeys(layer.fields) {
+			fmt.Fprintf(b, "%s  - %s: `%v`\n", indent, k, layer.fields[k])
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package errors
+
+import (
+	goerr "errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func renderTestChain() error {
+	root := Msg("not found").Code("not_found").With("user_id", 42).Err()
+	return WithErrorAndFields(root, goerr.New("lookup failed"), map[string]interface{}{"region": "eu west"})
+}
+
+func TestRenderers(t *testing.T) {
+	err := renderTestChain()
+	agg := Aggregate(err, goerr.New("plain"))
+
+	t.Run("should render the legacy format by default", func(t *testing.T) {
+		assert.Equal(t, LegacyRenderer{}.Render(err), err.Error())
+		assert.Equal(t, "Message: lookup failed. Location: github.com/hantonelli/errors/render_test.go:13. "+
+			"Fields: map[region:eu west]. <br> Message: not found. Code: not_found. "+
+			"Location: github.com/hantonelli/errors/render_test.go:12. Fields: map[user_id:42].", err.Error())
+	})
+
+	t.Run("should render in a single line", func(t *testing.T) {
+		assert.Equal(t, "lookup failed [github.com/hantonelli/errors/render_test.go:13 region=eu west]: "+
+			"not found [github.com/hantonelli/errors/render_test.go:12 code=not_found user_id=42]",
+			CompactRenderer{}.Render(err))
+		assert.Equal(t, "2 errors: [1] lookup failed [github.com/hantonelli/errors/render_test.go:13 region=eu west]: "+
+			"not found [github.com/hantonelli/errors/render_test.go:12 code=not_found user_id=42]; [2] plain",
+			CompactRenderer{}.Render(agg))
+	})
+
+	t.Run("should render in indented lines", func(t *testing.T) {
+		assert.Equal(t, "lookup failed\n"+
+			"    location: github.com/hantonelli/errors/render_test.go:13\n"+
+			"    region: eu west\n"+
+			"caused by: not found\n"+
+			"    code: not_found\n"+
+			"    location: github.com/hantonelli/errors/render_test.go:12\n"+
+			"    user_id: 42", MultilineRenderer{}.Render(err))
+		assert.Equal(t, "2 errors:\n"+
+			"    [1]\n"+
+			"        lookup failed\n"+
+			"            location: github.com/hantonelli/errors/render_test.go:13\n"+
+			"            region: eu west\n"+
+			"        caused by: not found\n"+
+			"            code: not_found\n"+
+			"            location: github.com/hantonelli/errors/render_test.go:12\n"+
+			"            user_id: 42\n"+
+			"    [2]\n"+
+			"        plain", MultilineRenderer{}.Render(agg))
+	})
+
+	t.Run("should render as logfmt", func(t *testing.T) {
+		assert.Equal(t, `msg="lookup failed" location=github.com/hantonelli/errors/render_test.go:13 region="eu west" `+
+			`cause1.msg="not found" cause1.code=not_found cause1.location=github.com/hantonelli/errors/render_test.go:12 `+
+			`cause1.user_id=42`, LogfmtRenderer{}.Render(err))
+		assert.Equal(t, `errors=2 err1.msg="lookup failed" err1.location=github.com/hantonelli/errors/render_test.go:13 `+
+			`err1.region="eu west" err1.cause1.msg="not found" err1.cause1.code=not_found `+
+			`err1.cause1.location=github.com/hantonelli/errors/render_test.go:12 err1.cause1.user_id=42 err2.msg=plain`,
+			LogfmtRenderer{}.Render(agg))
+	})
+
+	t.Run("should render as markdown", func(t *testing.T) {
+		assert.Equal(t, "- **lookup failed**\n"+
+			"  - Location: `github.com/hantonelli/errors/render_test.go:13`\n"+
+			"  - region: `eu west`\n"+
+			"- **not found**\n"+
+			"  - Code: `not_found`\n"+
+			"  - Location: `github.com/hantonelli/errors/render_test.go:12`\n"+
+			"  - user_id: `42`", MarkdownRenderer{}.Render(err))
+		assert.Equal(t, "- **2 errors**\n"+
+			"  1. Error 1\n"+
+			"     - **lookup failed**\n"+
+			"       - Location: `github.com/hantonelli/errors/render_test.go:13`\n"+
+			"       - region: `eu west`\n"+
+			"     - **not found**\n"+
+			"       - Code: `not_found`\n"+
+			"       - Location: `github.com/hantonelli/errors/render_test.go:12`\n"+
+			"       - user_id: `42`\n"+
+			"  2. Error 2\n"+
+			"     - **plain**", MarkdownRenderer{}.Render(agg))
+	})
+
+	t.Run("should redact sensitive fields", func(t *testing.T) {
+		secret := NewWithMsgAndFields("login failed", map[string]interface{}{"password": "hunter2"})
+		assert.Equal(t, "login failed [github.com/hantonelli/errors/render_test.go:89 password=[REDACTED]]",
+			CompactRenderer{}.Render(secret))
+	})
+}
+
+func TestSetRenderer(t *testing.T) {
+	defer SetRenderer(nil)
+	err := renderTestChain()
+	agg := Aggregate(err)
+
+	t.Run("should render Error with the renderer set", func(t *testing.T) {
+		SetRenderer(CompactRenderer{})
+		assert.Equal(t, CompactRenderer{}.Render(err), err.Error())
+		assert.Equal(t, CompactRenderer{}.Render(agg), agg.Error())
+		assert.Equal(t, CompactRenderer{}.Render(err), fmt.Sprintf("%v", err))
+	})
+
+	t.Run("should accept a function as a renderer", func(t *testing.T) {
+		SetRenderer(RendererFunc(func(err error) string {
+			return "rendered"
+		}))
+		assert.Equal(t, "rendered", err.Error())
+	})
+
+	t.Run("should restore the legacy renderer", func(t *testing.T) {
+		SetRenderer(nil)
+		assert.Equal(t, LegacyRenderer{}.Render(err), err.Error())
+	})
+}
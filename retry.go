@@ -0,0 +1,206 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+const (
+	// RetryableField is the field that marks a chain as retryable, or not, when it holds a bool. It
+	// takes precedence over every other way of classifying the chain.
+	RetryableField = "retryable"
+	// RetryAfterField is the field with the delay to wait before the next attempt, as a time.Duration,
+	// a number of seconds or a time.Time.
+	RetryAfterField = "retry_after"
+	// AttemptField is the field where Retry keeps the number of the attempt that failed, from 1.
+	AttemptField = "attempt"
+	// ElapsedField is the field where Retry keeps the time.Duration elapsed since the first attempt
+	// when the attempt failed.
+	ElapsedField = "elapsed"
+)
+
+// ErrAttemptFailed is the actual error of the layer that Retry adds, with the attempt and elapsed
+// fields, on top of the error of every failed attempt.
+var ErrAttemptFailed = errors.New("attempt failed")
+
+// RetryPolicy specifies how many times and how often Retry calls a function.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts. Zero means no limit when MaxElapsed is set.
+	MaxAttempts int
+	// MaxElapsed is the maximum time since the first attempt after which no attempt is started. Zero
+	// means no limit.
+	MaxElapsed time.Duration
+	// InitialDelay is the delay before the second attempt.
+	InitialDelay time.Duration
+	// MaxDelay caps the delay between attempts. A negative value means no cap.
+	MaxDelay time.Duration
+	// Multiplier is the factor applied to the delay after each attempt.
+	Multiplier float64
+	// Jitter is the fraction of the delay that is randomized, from 0 to 1. A negative value means no
+	// jitter.
+	Jitter float64
+}
+
+// DefaultRetryPolicy is the policy whose values are used for the fields of a RetryPolicy that are zero.
+// MaxAttempts only takes its default when MaxElapsed is zero too.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:  5,
+	InitialDelay: 100 * time.Millisecond,
+	MaxDelay:     10 * time.Second,
+	Multiplier:   2,
+	Jitter:       0.2,
+}
+
+var (
+	retryableCodesMu sync.RWMutex
+	retryableCodes   = map[Code]bool{}
+)
+
+// RegisterRetryableCode marks the chains with the provided code as retryable.
+func RegisterRetryableCode(code Code) {
+	retryableCodesMu.Lock()
+	defer retryableCodesMu.Unlock()
+	retryableCodes[code] = true
+}
+
+// IsRetryable returns whether the chain describes a failure worth retrying. The RetryableField wins when
+// it is set, otherwise the chain is retryable when its code was registered with RegisterRetryableCode
+// or when any error of the chain has a Temporary() or Timeout() method returning true.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	fields := mergeFieldSources(fieldSources(err), OuterWins)
+	if retryable, ok := fields[RetryableField].(bool); ok {
+		return retryable
+	}
+
+	retryableCodesMu.RLock()
+	registered := retryableCodes[CodeOf(err)]
+	retryableCodesMu.RUnlock()
+	if registered {
+		return true
+	}
+
+	_, found := Find(err, func(l Layer) bool {
+		return isTemporary(l.Err) || isTemporary(l.Actual)
+	})
+	return found
+}
+
+func isTemporary(err error) bool {
+	if temporary, ok := err.(interface{ Temporary() bool }); ok && temporary.Temporary() {
+		return true
+	}
+	if timeout, ok := err.(interface{ Timeout() bool }); ok && timeout.Timeout() {
+		return true
+	}
+	return false
+}
+
+// RetryAfter returns the delay requested by the RetryAfterField of the chain, if any.
+func RetryAfter(err error) (time.Duration, bool) {
+	fields := mergeFieldSources(fieldSources(err), OuterWins)
+	switch after := fields[RetryAfterField].(type) {
+	case time.Duration:
+		return after, true
+	case int:
+		return time.Duration(after) * time.Second, true
+	case int64:
+		return time.Duration(after) * time.Second, true
+	case float64:
+		return time.Duration(after * float64(time.Second)), true
+	case time.Time:
+		return time.Until(after), true
+	}
+	return 0, false
+}
+
+// Retry calls fn until it succeeds, it fails with an error that is not retryable, the attempts or the
+// time allowed by the policy run out, or ctx is done. It waits between attempts with exponential
+// backoff, or for the delay requested by the RetryAfterField of the error.
+//
+// When it gives up it returns an AggregateError with the error of every attempt, each in an
+// ErrAttemptFailed layer with the AttemptField and ElapsedField fields, followed by the cause of ctx
+// when ctx is done.
+func Retry(ctx context.Context, policy RetryPolicy, fn func() error) error {
+	pc := callerPC(1)
+	policy = policy.withDefaults()
+	start := time.Now()
+	var errs []error
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		elapsed := time.Since(start)
+		errs = append(errs, &WrappedErrorImpl{
+			actual:   ErrAttemptFailed,
+			previous: err,
+			fields:   map[string]interface{}{AttemptField: attempt, ElapsedField: elapsed},
+			pc:       pc,
+		})
+
+		if !IsRetryable(err) || (policy.MaxAttempts > 0 && attempt >= policy.MaxAttempts) {
+			return Combine(errs...)
+		}
+		delay, ok := RetryAfter(err)
+		if !ok {
+			delay = policy.delay(attempt)
+		}
+		if delay < 0 {
+			delay = 0
+		}
+		if policy.MaxElapsed > 0 && elapsed+delay >= policy.MaxElapsed {
+			return Combine(errs...)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return Combine(append(errs, context.Cause(ctx))...)
+		case <-timer.C:
+		}
+	}
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts == 0 && p.MaxElapsed == 0 {
+		p.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if p.InitialDelay == 0 {
+		p.InitialDelay = DefaultRetryPolicy.InitialDelay
+	}
+	if p.MaxDelay == 0 {
+		p.MaxDelay = DefaultRetryPolicy.MaxDelay
+	}
+	if p.Multiplier == 0 {
+		p.Multiplier = DefaultRetryPolicy.Multiplier
+	}
+	if p.Jitter == 0 {
+		p.Jitter = DefaultRetryPolicy.Jitter
+	}
+	return p
+}
+
+// delay returns the backoff after the provided attempt.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	delay := float64(p.InitialDelay) * math.Pow(p.Multiplier, float64(attempt-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		delay *= 1 - jitter + 2*jitter*rand.Float64()
+	}
+	if delay > math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(delay)
+}
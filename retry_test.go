@@ -0,0 +1,172 @@
+package errors
+
+import (
+	"context"
+	goerr "errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string { return "i/o timeout" }
+func (timeoutError) Timeout() bool { return true }
+
+func TestIsRetryable(t *testing.T) {
+	t.Run("should not retry a plain error", func(t *testing.T) {
+		assert.False(t, IsRetryable(goerr.New("plain")))
+		assert.False(t, IsRetryable(nil))
+	})
+
+	t.Run("should retry an error with a Timeout method anywhere in the chain", func(t *testing.T) {
+		assert.True(t, IsRetryable(NewWithErrorAndFields(timeoutError{}, nil)))
+		assert.True(t, IsRetryable(WithError(timeoutError{}, goerr.New("read"))))
+	})
+
+	t.Run("should retry a registered code", func(t *testing.T) {
+		RegisterRetryableCode("retry_test_unavailable")
+		assert.True(t, IsRetryable(Msg("unavailable").Code("retry_test_unavailable").Err()))
+		assert.False(t, IsRetryable(Msg("invalid").Code("retry_test_invalid").Err()))
+	})
+
+	t.Run("should let the retryable field win", func(t *testing.T) {
+		assert.True(t, IsRetryable(Msg("busy").With(RetryableField, true).Err()))
+		inner := NewWithErrorAndFields(timeoutError{}, nil)
+		assert.False(t, IsRetryable(Msg("give up").With(RetryableField, false).Wrap(inner).Err()))
+	})
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Run("should read durations and seconds", func(t *testing.T) {
+		after, ok := RetryAfter(Msg("slow down").With(RetryAfterField, 2*time.Second).Err())
+		assert.True(t, ok)
+		assert.Equal(t, 2*time.Second, after)
+
+		after, ok = RetryAfter(Msg("slow down").With(RetryAfterField, 3).Err())
+		assert.True(t, ok)
+		assert.Equal(t, 3*time.Second, after)
+	})
+
+	t.Run("should report no hint", func(t *testing.T) {
+		_, ok := RetryAfter(goerr.New("plain"))
+		assert.False(t, ok)
+	})
+}
+
+func TestRetry(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond}
+
+	t.Run("should return nil once an attempt succeeds", func(t *testing.T) {
+		attempts := 0
+		err := Retry(context.Background(), policy, func() error {
+			attempts++
+			if attempts < 2 {
+				return timeoutError{}
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, attempts)
+	})
+
+	t.Run("should keep every attempt when it runs out of attempts", func(t *testing.T) {
+		attempts := 0
+		err := Retry(context.Background(), policy, func() error {
+			attempts++
+			return timeoutError{}
+		})
+		assert.Equal(t, 3, attempts)
+
+		agg, ok := err.(*AggregateError)
+		assert.True(t, ok)
+		assert.Len(t, agg.Errors(), 3)
+		for i, attemptErr := range agg.Errors() {
+			wrapped := attemptErr.(*WrappedErrorImpl)
+			assert.Equal(t, ErrAttemptFailed, wrapped.GetActual())
+			assert.Equal(t, i+1, wrapped.GetFields()[AttemptField])
+			assert.IsType(t, time.Duration(0), wrapped.GetFields()[ElapsedField])
+			assert.Equal(t, "github.com/hantonelli/errors/retry_test.go:76", wrapped.location().String())
+		}
+		assert.True(t, goerr.Is(err, timeoutError{}))
+	})
+
+	t.Run("should stop at the first error that is not retryable", func(t *testing.T) {
+		attempts := 0
+		errInvalid := goerr.New("invalid")
+		err := Retry(context.Background(), policy, func() error {
+			attempts++
+			return errInvalid
+		})
+		assert.Equal(t, 1, attempts)
+		assert.True(t, goerr.Is(err, errInvalid))
+	})
+
+	t.Run("should honor the retry after hint", func(t *testing.T) {
+		attempts := 0
+		start := time.Now()
+		err := Retry(context.Background(), policy, func() error {
+			attempts++
+			if attempts == 1 {
+				return Msg("slow down").With(RetryableField, true).With(RetryAfterField, 20*time.Millisecond).Err()
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	})
+
+	t.Run("should give up when the next attempt would exceed the elapsed time", func(t *testing.T) {
+		attempts := 0
+		err := Retry(context.Background(), RetryPolicy{MaxElapsed: 50 * time.Millisecond, InitialDelay: time.Second}, func() error {
+			attempts++
+			return timeoutError{}
+		})
+		assert.Equal(t, 1, attempts)
+		assert.Len(t, err.(*AggregateError).Errors(), 1)
+	})
+
+	t.Run("should stop waiting when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		err := Retry(ctx, RetryPolicy{MaxAttempts: 3, InitialDelay: time.Hour}, func() error {
+			cancel()
+			return timeoutError{}
+		})
+		assert.True(t, goerr.Is(err, context.Canceled))
+		assert.Len(t, err.(*AggregateError).Errors(), 2)
+	})
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	t.Run("should grow exponentially up to the max delay", func(t *testing.T) {
+		policy := RetryPolicy{InitialDelay: 10 * time.Millisecond, Multiplier: 2, MaxDelay: 30 * time.Millisecond}
+		assert.Equal(t, 10*time.Millisecond, policy.delay(1))
+		assert.Equal(t, 20*time.Millisecond, policy.delay(2))
+		assert.Equal(t, 30*time.Millisecond, policy.delay(3))
+	})
+
+	t.Run("should fill in the fields that are not set from the default policy", func(t *testing.T) {
+		policy := RetryPolicy{MaxAttempts: 3}.withDefaults()
+		assert.Equal(t, RetryPolicy{
+			MaxAttempts:  3,
+			InitialDelay: DefaultRetryPolicy.InitialDelay,
+			MaxDelay:     DefaultRetryPolicy.MaxDelay,
+			Multiplier:   DefaultRetryPolicy.Multiplier,
+			Jitter:       DefaultRetryPolicy.Jitter,
+		}, policy)
+
+		policy = RetryPolicy{MaxElapsed: time.Minute, MaxDelay: -1, Jitter: -1}.withDefaults()
+		assert.Equal(t, 0, policy.MaxAttempts)
+		assert.Equal(t, 100*time.Millisecond*(1<<20), policy.delay(21))
+	})
+
+	t.Run("should keep the jittered delay within its bounds", func(t *testing.T) {
+		policy := RetryPolicy{InitialDelay: 100 * time.Millisecond, Multiplier: 2, Jitter: 0.5}
+		for i := 0; i < 100; i++ {
+			delay := policy.delay(1)
+			assert.GreaterOrEqual(t, delay, 50*time.Millisecond)
+			assert.LessOrEqual(t, delay, 150*time.Millisecond)
+		}
+	})
+}
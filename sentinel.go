@@ -0,0 +1,41 @@
+package errors
+
+import "reflect"
+
+// Sentinel is an error declared once, usually in a package level variable, and matched by identity, so
+// another error with the same message never matches it.
+//
+//	var ErrUserNotFound = errors.NewSentinel("user not found")
+type Sentinel struct {
+	msg string
+}
+
+// NewSentinel returns a new Sentinel with the provided message.
+func NewSentinel(msg string) *Sentinel {
+	return &Sentinel{msg: msg}
+}
+
+// Error returns the message of the sentinel.
+func (s *Sentinel) Error() string {
+	return s.msg
+}
+
+// Matcher reports whether err, an error of a chain, is the error looked for.
+type Matcher func(lookFor, err error) bool
+
+// MatchIdentity matches the errors that are equal to lookFor with ==. Errors whose dynamic type is not
+// comparable never match, instead of panicking.
+func MatchIdentity(lookFor, err error) bool {
+	if lookFor == nil || err == nil || reflect.TypeOf(lookFor) != reflect.TypeOf(err) {
+		return false
+	}
+	if !reflect.ValueOf(err).Comparable() || !reflect.ValueOf(lookFor).Comparable() {
+		return false
+	}
+	return lookFor == err
+}
+
+// MatchMessage matches the errors whose Error() is the same as the one of lookFor.
+func MatchMessage(lookFor, err error) bool {
+	return lookFor != nil && err != nil && lookFor.Error() == err.Error()
+}
@@ -0,0 +1,78 @@
+package errors
+
+import (
+	goerr "errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sliceError []string
+
+func (e sliceError) Error() string { return "slice error" }
+
+func TestSentinel(t *testing.T) {
+	errNotFound := NewSentinel("not found")
+
+	t.Run("should find a sentinel wrapped in a chain", func(t *testing.T) {
+		err := WithErrorAndFields(NewWithErrorAndFields(errNotFound, fields), goerr.New("lookup failed"), nil)
+
+		found, foundFields, ok := ContainsError(errNotFound, err)
+		assert.True(t, ok)
+		assert.Same(t, errNotFound, found)
+		assert.Equal(t, fields, foundFields)
+		assert.True(t, goerr.Is(err, errNotFound))
+	})
+
+	t.Run("should not match another error with the same message", func(t *testing.T) {
+		err := NewWithErrorAndFields(NewSentinel("not found"), fields)
+		_, _, ok := ContainsError(errNotFound, err)
+		assert.False(t, ok)
+
+		_, _, ok = ContainsError(errNotFound, NewWithMsg("not found"))
+		assert.False(t, ok)
+	})
+}
+
+func TestContainsErrorBy(t *testing.T) {
+	t.Run("should find a wrapped error looked for by identity", func(t *testing.T) {
+		lookFor := NewWithMsgAndFields("inner", fields)
+		err := WithError(lookFor, goerr.New("outer"))
+
+		found, foundFields, ok := ContainsError(lookFor, err)
+		assert.True(t, ok)
+		assert.Same(t, lookFor, found)
+		assert.Equal(t, fields, foundFields)
+	})
+
+	t.Run("should match by message when it is asked to", func(t *testing.T) {
+		err := NewWithErrorAndFields(goerr.New("expected error"), fields)
+
+		_, _, ok := ContainsError(goerr.New("expected error"), err)
+		assert.False(t, ok)
+
+		found, foundFields, ok := ContainsErrorBy(goerr.New("expected error"), err, MatchMessage)
+		assert.True(t, ok)
+		assert.Equal(t, "expected error", found.Error())
+		assert.Equal(t, fields, foundFields)
+	})
+
+	t.Run("should not panic on errors that are not comparable", func(t *testing.T) {
+		lookFor := sliceError{"a"}
+		err := NewWithErrorAndFields(sliceError{"a"}, fields)
+
+		assert.NotPanics(t, func() {
+			_, _, ok := ContainsError(lookFor, err)
+			assert.False(t, ok)
+		})
+		_, _, ok := ContainsErrorBy(lookFor, err, MatchMessage)
+		assert.True(t, ok)
+	})
+
+	t.Run("should return nil with a nil matcher input", func(t *testing.T) {
+		found, foundFields, ok := ContainsErrorBy(nil, goerr.New("error"), MatchMessage)
+		assert.False(t, ok)
+		assert.Nil(t, found)
+		assert.Equal(t, map[string]interface{}{}, foundFields)
+	})
+}
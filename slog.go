@@ -0,0 +1,163 @@
+package errors
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync/atomic"
+)
+
+var logStacktrace int32
+
+// SetLogStacktrace sets whether LogValue includes the stack trace of the chain.
+func SetLogStacktrace(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&logStacktrace, v)
+}
+
+// LogValue implements slog.LogValuer. It returns a group with the message of every layer, the template
+// of the message if any, the code, the merged fields, the location of every layer and, when enabled
+// with SetLogStacktrace, the stack trace.
+func (e *WrappedErrorImpl) LogValue() slog.Value {
+	var messages, locations []string
+	Walk(e, func(l Layer) bool {
+		if _, isWrappedError := l.Err.(WrappedError); isWrappedError {
+			messages = append(messages, l.Actual.Error())
+			locations = append(locations, l.Location)
+		} else if len(unwrapCauses(l.Err)) == 0 {
+			messages = append(messages, l.Err.Error())
+		}
+		return true
+	})
+	attrs := []slog.Attr{
+		slog.String("message", e.actual.Error()),
+		slog.Any("chain", messages),
+	}
+	if template := TemplateOf(e); template != "" {
+		attrs = append(attrs, slog.String("template", template))
+	}
+	if code := CodeOf(e); code != "" {
+		attrs = append(attrs, slog.String("code", string(code)))
+	}
+	attrs = append(attrs,
+		slog.Attr{Key: "fields", Value: slog.GroupValue(fieldAttrs(e.GetAllFields())...)},
+		slog.Any("locations", locations),
+	)
+	if atomic.LoadInt32(&logStacktrace) == 1 {
+		var stack []string
+		for _, frame := range e.GetStackFrames() {
+			stack = append(stack, frame.String())
+		}
+		attrs = append(attrs, slog.Any("stack", stack))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// fieldAttrs returns the fields as attributes sorted by key.
+func fieldAttrs(fields map[string]interface{}) []slog.Attr {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	attrs := make([]slog.Attr, 0, len(keys))
+	for _, k := range keys {
+		attrs = append(attrs, slog.Any(k, fields[k]))
+	}
+	return attrs
+}
+
+// NewSlogHandler returns a slog.Handler that passes records to next, adding the merged fields of every
+// error attribute, of the record or of the logger, as attributes of their own at the top level, outside
+// of any group.
+func NewSlogHandler(next slog.Handler) slog.Handler {
+	return &fieldsHandler{next: next}
+}
+
+// fieldsHandler keeps the groups opened on it, with the attributes added in each one, instead of
+// passing them to next, so the promoted fields can be added outside of them.
+type fieldsHandler struct {
+	next     slog.Handler
+	groups   []slogGroup
+	promoted []slog.Attr
+}
+
+type slogGroup struct {
+	name  string
+	attrs []slog.Attr
+}
+
+func (h *fieldsHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *fieldsHandler) Handle(ctx context.Context, r slog.Record) error {
+	var attrs []slog.Attr
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	promoted := append(append([]slog.Attr(nil), h.promoted...), promotedAttrs(attrs)...)
+	if len(h.groups) == 0 {
+		if len(promoted) == 0 {
+			return h.next.Handle(ctx, r)
+		}
+		r = r.Clone()
+		r.AddAttrs(promoted...)
+		return h.next.Handle(ctx, r)
+	}
+
+	for i := len(h.groups) - 1; i >= 0; i-- {
+		group := h.groups[i]
+		attrs = []slog.Attr{{Key: group.name, Value: slog.GroupValue(append(append([]slog.Attr(nil), group.attrs...), attrs...)...)}}
+	}
+	grouped := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	grouped.AddAttrs(attrs...)
+	grouped.AddAttrs(promoted...)
+	return h.next.Handle(ctx, grouped)
+}
+
+func (h *fieldsHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	with := &fieldsHandler{
+		next:     h.next,
+		groups:   h.groups,
+		promoted: append(append([]slog.Attr(nil), h.promoted...), promotedAttrs(attrs)...),
+	}
+	if len(h.groups) == 0 {
+		with.next = h.next.WithAttrs(attrs)
+		return with
+	}
+	with.groups = append([]slogGroup(nil), h.groups...)
+	last := &with.groups[len(with.groups)-1]
+	last.attrs = append(append([]slog.Attr(nil), last.attrs...), attrs...)
+	return with
+}
+
+func (h *fieldsHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &fieldsHandler{
+		next:     h.next,
+		groups:   append(append([]slogGroup(nil), h.groups...), slogGroup{name: name}),
+		promoted: h.promoted,
+	}
+}
+
+// promotedAttrs returns the merged fields of the errors of attrs as attributes.
+func promotedAttrs(attrs []slog.Attr) []slog.Attr {
+	var promoted []slog.Attr
+	for _, a := range attrs {
+		err, isError := a.Value.Any().(error)
+		if !isError {
+			continue
+		}
+		if carrier, _, ok := Contains[interface{ GetAllFields() map[string]interface{} }](err); ok {
+			promoted = append(promoted, fieldAttrs(carrier.GetAllFields())...)
+		}
+	}
+	return promoted
+}
@@ -0,0 +1,113 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	goerr "errors"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func logJSON(t *testing.T, handler func(*bytes.Buffer) slog.Handler, args ...interface{}) map[string]interface{} {
+	t.Helper()
+	var b bytes.Buffer
+	slog.New(handler(&b)).Error("request failed", args...)
+	entry := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(b.Bytes(), &entry))
+	return entry
+}
+
+func jsonHandler(b *bytes.Buffer) slog.Handler {
+	return slog.NewJSONHandler(b, nil)
+}
+
+func TestLogValue(t *testing.T) {
+
+	t.Run("should log the message chain, fields and locations", func(t *testing.T) {
+		entry := logJSON(t, jsonHandler, "err", getThirdWrap())
+		logged := entry["err"].(map[string]interface{})
+
+		assert.Equal(t, "third wrap", logged["message"])
+		assert.Equal(t, []interface{}{"third wrap", "second wrap", "previous"}, logged["chain"])
+		assert.Equal(t, "test-string", logged["fields"].(map[string]interface{})["first-wrap-string"])
+		assert.Len(t, logged["fields"], 6)
+		assert.Equal(t, []interface{}{
+			"github.com/hantonelli/errors/wrappederror_helper_test.go:18",
+			"github.com/hantonelli/errors/wrappederror_helper_test.go:27",
+			"github.com/hantonelli/errors/wrappederror_helper_test.go:35",
+		}, logged["locations"])
+		assert.NotContains(t, logged, "stack")
+		assert.NotContains(t, logged, "code")
+	})
+
+	t.Run("should log the code and the stack when enabled", func(t *testing.T) {
+		SetLogStacktrace(true)
+		defer SetLogStacktrace(false)
+
+		err := WithError(goerr.New("previous"), goerr.New("actual")).WithCode("slog_code")
+		entry := logJSON(t, jsonHandler, "err", NewWithError(err))
+		logged := entry["err"].(map[string]interface{})
+		assert.Equal(t, "slog_code", logged["code"])
+		assert.Contains(t, logged["chain"], "previous")
+		assert.NotEmpty(t, logged["stack"])
+	})
+}
+
+func TestSlogHandler(t *testing.T) {
+	handler := func(b *bytes.Buffer) slog.Handler {
+		return NewSlogHandler(slog.NewJSONHandler(b, nil))
+	}
+
+	t.Run("should promote the fields of an error attribute", func(t *testing.T) {
+		err := fmt.Errorf("handler: %w", NewWithMsgAndFields("not found", map[string]interface{}{"user_id": 42}))
+		entry := logJSON(t, handler, "err", err, "path", "/users")
+		assert.Equal(t, float64(42), entry["user_id"])
+		assert.Equal(t, "/users", entry["path"])
+		assert.Contains(t, entry["err"], "not found")
+	})
+
+	t.Run("should leave records without wrapped errors untouched", func(t *testing.T) {
+		entry := logJSON(t, handler, "err", goerr.New("plain"), "path", "/users")
+		assert.Equal(t, "plain", entry["err"])
+		assert.Len(t, entry, 5)
+	})
+
+	t.Run("should keep working with attributes and groups", func(t *testing.T) {
+		var b bytes.Buffer
+		logger := slog.New(handler(&b)).With("service", "users").WithGroup("req")
+		logger.Error("failed", "err", NewWithMsgAndFields("not found", map[string]interface{}{"user_id": 42}))
+		entry := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal(b.Bytes(), &entry))
+		assert.Equal(t, "users", entry["service"])
+		assert.Equal(t, float64(42), entry["user_id"])
+		assert.Equal(t, "not found", entry["req"].(map[string]interface{})["err"].(map[string]interface{})["message"])
+		assert.NotContains(t, entry["req"], "user_id")
+	})
+
+	t.Run("should promote the fields of an error added to the logger", func(t *testing.T) {
+		var b bytes.Buffer
+		err := NewWithMsgAndFields("not found", map[string]interface{}{"tenant": "acme"})
+		slog.New(handler(&b)).With("err", err).Error("failed", "path", "/users")
+		entry := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal(b.Bytes(), &entry))
+		assert.Equal(t, "acme", entry["tenant"])
+		assert.Equal(t, "/users", entry["path"])
+	})
+
+	t.Run("should nest the attributes of every group in order", func(t *testing.T) {
+		var b bytes.Buffer
+		err := NewWithMsgAndFields("not found", map[string]interface{}{"tenant": "acme"})
+		logger := slog.New(handler(&b)).WithGroup("a").With("x", 1).WithGroup("b").With("err", err)
+		logger.Error("failed", "y", 2)
+		entry := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal(b.Bytes(), &entry))
+		a := entry["a"].(map[string]interface{})
+		assert.Equal(t, float64(1), a["x"])
+		assert.Equal(t, float64(2), a["b"].(map[string]interface{})["y"])
+		assert.Equal(t, "not found", a["b"].(map[string]interface{})["err"].(map[string]interface{})["message"])
+		assert.Equal(t, "acme", entry["tenant"])
+	})
+}
@@ -0,0 +1,109 @@
+package errors
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const truncatedMarker = "..."
+
+var maxStackDepth int64 = 20
+
+// Frame describes a single frame of a stack trace.
+type Frame struct {
+	Function string
+	Package  string
+	File     string
+	Line     int
+}
+
+// String returns the frame in the filename.go:123 format.
+func (f Frame) String() string {
+	return fmt.Sprintf("%s:%d", f.File, f.Line)
+}
+
+// SetMaxStackDepth sets the maximum number of frames captured for the first error in a chain. Deeper
+// stacks are truncated, and a depth of 0 disables stack capture.
+func SetMaxStackDepth(depth int) {
+	if depth < 0 {
+		depth = 0
+	}
+	atomic.StoreInt64(&maxStackDepth, int64(depth))
+}
+
+// frameCache holds the frame of every program counter symbolized so far, with the file name untrimmed.
+var frameCache sync.Map
+
+// captureStack returns the program counters of the stack starting skip frames above its caller, and
+// whether frames were dropped. They are only symbolized when the frames are needed.
+func captureStack(skip int) ([]uintptr, bool) {
+	depth := int(atomic.LoadInt64(&maxStackDepth))
+	if depth == 0 {
+		return nil, false
+	}
+	pcs := make([]uintptr, depth+1)
+	n := runtime.Callers(skip+2, pcs)
+	if n > depth {
+		return pcs[:depth], true
+	}
+	return pcs[:n], false
+}
+
+// callerPC returns the program counter of the frame skip frames above its caller.
+func callerPC(skip int) uintptr {
+	var pcs [1]uintptr
+	if runtime.Callers(skip+2, pcs[:]) == 0 {
+		return 0
+	}
+	return pcs[0]
+}
+
+// resolveFrames returns the frames of the given program counters with their file names trimmed.
+func resolveFrames(pcs []uintptr) []Frame {
+	if len(pcs) == 0 {
+		return nil
+	}
+	frames := make([]Frame, 0, len(pcs))
+	for _, pc := range pcs {
+		frame := symbolize(pc)
+		frame.File = cleanFilePath(frame.File)
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+// symbolize returns the frame of a program counter returned by runtime.Callers, caching it per counter.
+func symbolize(pc uintptr) Frame {
+	if cached, ok := frameCache.Load(pc); ok {
+		return cached.(Frame)
+	}
+	caller, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	frame := newFrame(caller)
+	frameCache.Store(pc, frame)
+	return frame
+}
+
+func newFrame(caller runtime.Frame) Frame {
+	pkg, function := splitFunctionName(caller.Function)
+	return Frame{
+		Function: function,
+		Package:  pkg,
+		File:     caller.File,
+		Line:     caller.Line,
+	}
+}
+
+// splitFunctionName splits a fully qualified function name such as github.com/a/b.(*T).M into its
+// package path and the function name within the package.
+func splitFunctionName(name string) (string, string) {
+	lastSlash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[lastSlash+1:], ".")
+	if dot < 0 {
+		return "", name
+	}
+	dot += lastSlash + 1
+	return name[:dot], name[dot+1:]
+}
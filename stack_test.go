@@ -0,0 +1,75 @@
+package errors
+
+import (
+	goerr "errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func recurseNewWithMsg(depth int) *WrappedErrorImpl {
+	if depth == 0 {
+		return NewWithMsg("deep")
+	}
+	return recurseNewWithMsg(depth - 1)
+}
+
+func TestGetStackFrames(t *testing.T) {
+
+	t.Run("should return the frames of the first error in the chain", func(t *testing.T) {
+		err := getThirdWrap()
+		we, ok := err.(WrappedError)
+		if !ok {
+			t.Error("Error is not WrappedError")
+		}
+		frames := we.GetStackFrames()
+		assert.True(t, len(frames) > 3)
+		assert.Equal(t, Frame{
+			Function: "getFirstWrapped",
+			Package:  "github.com/hantonelli/errors",
+			File:     "github.com/hantonelli/errors/wrappederror_helper_test.go",
+			Line:     35,
+		}, frames[0])
+		assert.Equal(t, "getSecondWrap", frames[1].Function)
+		assert.Equal(t, "getThirdWrap", frames[2].Function)
+		assert.False(t, err.(*WrappedErrorImpl).IsStackTruncated())
+	})
+
+	t.Run("should return no frames when the previous error is not wrapped", func(t *testing.T) {
+		err := WithError(goerr.New("previous"), goerr.New("actual"))
+		assert.Empty(t, err.GetStackFrames())
+		assert.Equal(t, "", err.GetStacktrace())
+	})
+
+	t.Run("should truncate the stack to the maximum depth", func(t *testing.T) {
+		SetMaxStackDepth(3)
+		defer SetMaxStackDepth(20)
+
+		err := recurseNewWithMsg(5)
+		assert.Len(t, err.GetStackFrames(), 3)
+		assert.True(t, err.IsStackTruncated())
+		assert.True(t, strings.HasSuffix(err.GetStacktrace(), " ..."))
+		assert.True(t, strings.HasSuffix(fmt.Sprintf("%+v", err), "\t...\n"))
+	})
+
+	t.Run("should not capture the stack when the maximum depth is 0", func(t *testing.T) {
+		SetMaxStackDepth(0)
+		defer SetMaxStackDepth(20)
+
+		err := NewWithMsg("actual")
+		assert.Empty(t, err.GetStackFrames())
+		assert.False(t, err.IsStackTruncated())
+	})
+}
+
+func TestSplitFunctionName(t *testing.T) {
+	pkg, function := splitFunctionName("github.com/hantonelli/errors.(*WrappedErrorImpl).Error")
+	assert.Equal(t, "github.com/hantonelli/errors", pkg)
+	assert.Equal(t, "(*WrappedErrorImpl).Error", function)
+
+	pkg, function = splitFunctionName("main.main.func1")
+	assert.Equal(t, "main", pkg)
+	assert.Equal(t, "main.func1", function)
+}
@@ -0,0 +1,125 @@
+package errors
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TemplateMessage is the actual error of the errors created with NewWithTemplate. It keeps the
+// template, which is a stable key to group errors by, apart from the values it was filled in with.
+type TemplateMessage struct {
+	template string
+	values   map[string]interface{}
+	message  string
+	missing  []string
+	extra    []string
+}
+
+// NewWithTemplate returns a new WrappedErrorImpl with the provided fields and a message filled in from
+// the template, where every {key} is replaced by the value of the field key and {{ and }} stand for {
+// and }. Sensitive values are redacted in the message. A key without a field is left as it is in the
+// message, see TemplateMessage.Validate.
+func NewWithTemplate(template string, fields map[string]interface{}) *WrappedErrorImpl {
+	return From(NewTemplateMessage(template, fields)).Fields(fields).build()
+}
+
+// NewTemplateMessage returns a TemplateMessage filled in from the template and the provided fields, the
+// same way as NewWithTemplate.
+func NewTemplateMessage(template string, fields map[string]interface{}) *TemplateMessage {
+	t := &TemplateMessage{template: template, values: map[string]interface{}{}}
+	redacted := redactFields(fields)
+	var b strings.Builder
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		if (c == '{' || c == '}') && i+1 < len(template) && template[i+1] == c {
+			b.WriteByte(c)
+			i++
+			continue
+		}
+		end := strings.IndexByte(template[i+1:], '}')
+		if c != '{' || end <= 0 || strings.ContainsRune(template[i+1:i+1+end], '{') {
+			b.WriteByte(c)
+			continue
+		}
+		key := template[i+1 : i+1+end]
+		i += end + 1
+		if value, ok := fields[key]; ok {
+			t.values[key] = value
+			fmt.Fprintf(&b, "%v", redacted[key])
+			continue
+		}
+		if !containsString(t.missing, key) {
+			t.missing = append(t.missing, key)
+		}
+		fmt.Fprintf(&b, "{%s}", key)
+	}
+	t.message = b.String()
+
+	for key := range fields {
+		if _, used := t.values[key]; !used {
+			t.extra = append(t.extra, key)
+		}
+	}
+	sort.Strings(t.extra)
+	return t
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Error returns the filled in message.
+func (t *TemplateMessage) Error() string {
+	return t.message
+}
+
+// Template returns the template the message was filled in from.
+func (t *TemplateMessage) Template() string {
+	return t.template
+}
+
+// Values returns a copy of the original values of the keys used by the template.
+func (t *TemplateMessage) Values() map[string]interface{} {
+	return copyFields(t.values)
+}
+
+// MissingKeys returns the keys of the template that had no field, in the order they appear.
+func (t *TemplateMessage) MissingKeys() []string {
+	return append([]string(nil), t.missing...)
+}
+
+// ExtraKeys returns the fields that are not used by the template, sorted.
+func (t *TemplateMessage) ExtraKeys() []string {
+	return append([]string(nil), t.extra...)
+}
+
+// Validate returns an error describing the missing and extra keys, or nil when the template used
+// every field and every key had one.
+func (t *TemplateMessage) Validate() error {
+	var problems []string
+	if len(t.missing) > 0 {
+		problems = append(problems, fmt.Sprintf("missing keys %v", t.missing))
+	}
+	if len(t.extra) > 0 {
+		problems = append(problems, fmt.Sprintf("extra keys %v", t.extra))
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("template %q: %s", t.template, strings.Join(problems, ", "))
+}
+
+// TemplateOf returns the template of the first TemplateMessage of the chain, or an empty string when
+// there is none.
+func TemplateOf(err error) string {
+	if t, _, ok := Contains[*TemplateMessage](err); ok {
+		return t.Template()
+	}
+	return ""
+}
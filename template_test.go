@@ -0,0 +1,85 @@
+package errors
+
+import (
+	"encoding/json"
+	goerr "errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewWithTemplate(t *testing.T) {
+	template := "user {user_id} not found in {region}"
+
+	t.Run("should fill in the message and keep the template and values apart", func(t *testing.T) {
+		err := NewWithTemplate(template, map[string]interface{}{"user_id": 42, "region": "eu"})
+
+		assert.Equal(t, "user 42 not found in eu", err.GetActual().Error())
+		assert.Equal(t, "Message: user 42 not found in eu. Location: github.com/hantonelli/errors/template_test.go:15. "+
+			"Fields: map[region:eu user_id:42].", err.Error())
+
+		message := err.GetActual().(*TemplateMessage)
+		assert.Equal(t, template, message.Template())
+		assert.Equal(t, map[string]interface{}{"user_id": 42, "region": "eu"}, message.Values())
+		assert.NoError(t, message.Validate())
+		assert.Equal(t, template, TemplateOf(WithError(err, goerr.New("handler failed"))))
+	})
+
+	t.Run("should report missing and extra keys without panicking", func(t *testing.T) {
+		err := NewWithTemplate(template, map[string]interface{}{"user_id": 42, "tenant": "acme"})
+
+		message := err.GetActual().(*TemplateMessage)
+		assert.Equal(t, "user 42 not found in {region}", message.Error())
+		assert.Equal(t, []string{"region"}, message.MissingKeys())
+		assert.Equal(t, []string{"tenant"}, message.ExtraKeys())
+		assert.EqualError(t, message.Validate(),
+			`template "user {user_id} not found in {region}": missing keys [region], extra keys [tenant]`)
+		assert.Equal(t, "acme", err.GetFields()["tenant"])
+	})
+
+	t.Run("should handle nil fields, escaped and unterminated braces", func(t *testing.T) {
+		message := NewWithTemplate("{{literal}} {key} {", nil).GetActual().(*TemplateMessage)
+		assert.Equal(t, "{literal} {key} {", message.Error())
+		assert.Equal(t, []string{"key"}, message.MissingKeys())
+		assert.Empty(t, message.ExtraKeys())
+	})
+
+	t.Run("should redact sensitive values in the message only", func(t *testing.T) {
+		err := NewWithTemplate("login as {user} with {password}", map[string]interface{}{"user": "bob", "password": "hunter2"})
+
+		message := err.GetActual().(*TemplateMessage)
+		assert.Equal(t, "login as bob with [REDACTED]", message.Error())
+		assert.Equal(t, "hunter2", message.Values()["password"])
+	})
+
+	t.Run("should add the template to json", func(t *testing.T) {
+		err := NewWithTemplate(template, map[string]interface{}{"user_id": 42, "region": "eu"})
+		b, jsonErr := json.Marshal(err)
+		assert.NoError(t, jsonErr)
+
+		var decoded struct {
+			Layers []struct {
+				Message  string `json:"message"`
+				Template string `json:"template"`
+			} `json:"layers"`
+		}
+		assert.NoError(t, json.Unmarshal(b, &decoded))
+		assert.Equal(t, "user 42 not found in eu", decoded.Layers[0].Message)
+		assert.Equal(t, template, decoded.Layers[0].Template)
+	})
+
+	t.Run("should add the template to the log value", func(t *testing.T) {
+		err := NewWithTemplate(template, map[string]interface{}{"user_id": 42, "region": "eu"})
+		var logged string
+		for _, attr := range err.LogValue().Group() {
+			if attr.Key == "template" {
+				logged = attr.Value.String()
+			}
+		}
+		assert.Equal(t, template, logged)
+	})
+
+	t.Run("should return no template for other chains", func(t *testing.T) {
+		assert.Equal(t, "", TemplateOf(NewWithMsg("plain")))
+	})
+}
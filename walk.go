@@ -0,0 +1,123 @@
+package errors
+
+// Layer describes an error visited while walking an error chain.
+type Layer struct {
+	// Err is the error at this point of the chain: the wrapped error itself for a wrapped layer.
+	Err error
+	// Actual is the actual error of a wrapped layer, and the same as Err for any other error.
+	Actual error
+	// Fields are the fields of a wrapped layer. An error that is not wrapped has no fields of its own,
+	// so it gets the fields of the wrapped layer that holds it.
+	Fields map[string]interface{}
+	// Location is where the wrapped layer was created, following the same rule as Fields.
+	Location string
+	// Depth is the number of errors between the start of the chain and Err.
+	Depth int
+}
+
+// Walk visits every error of the chain, depth first and from the outermost error to the root. For
+// every wrapped layer it visits the layer, the errors wrapped by its actual error and then its previous
+// error. Any other error is visited and then followed through Unwrap() error or Unwrap() []error.
+// Walk stops as soon as visit returns false.
+func Walk(err error, visit func(Layer) bool) {
+	walk(err, Layer{Fields: map[string]interface{}{}}, 0, visit)
+}
+
+// Find returns the first layer of the chain accepted by pred.
+func Find(err error, pred func(Layer) bool) (Layer, bool) {
+	var found Layer
+	var ok bool
+	Walk(err, func(l Layer) bool {
+		if pred(l) {
+			found, ok = l, true
+			return false
+		}
+		return true
+	})
+	return found, ok
+}
+
+// FindAll returns every layer of the chain accepted by pred.
+func FindAll(err error, pred func(Layer) bool) []Layer {
+	var found []Layer
+	Walk(err, func(l Layer) bool {
+		if pred(l) {
+			found = append(found, l)
+		}
+		return true
+	})
+	return found
+}
+
+func walk(err error, holder Layer, depth int, visit func(Layer) bool) bool {
+	if err == nil {
+		return true
+	}
+	we, isWrappedError := err.(WrappedError)
+	if !isWrappedError {
+		if !visit(Layer{Err: err, Actual: err, Fields: holder.Fields, Location: holder.Location, Depth: depth}) {
+			return false
+		}
+		for _, cause := range unwrapCauses(err) {
+			if !walk(cause, holder, depth+1, visit) {
+				return false
+			}
+		}
+		return true
+	}
+
+	layer := Layer{Err: err, Actual: we.GetActual(), Fields: we.GetFields(), Depth: depth}
+	if impl, ok := err.(*WrappedErrorImpl); ok {
+		layer.Location = impl.location().String()
+	}
+	if !visit(layer) {
+		return false
+	}
+	if _, isActualWrapped := layer.Actual.(WrappedError); isActualWrapped {
+		if !walk(layer.Actual, layer, depth+1, visit) {
+			return false
+		}
+	} else {
+		for _, cause := range unwrapCauses(layer.Actual) {
+			if !walk(cause, layer, depth+1, visit) {
+				return false
+			}
+		}
+	}
+	return walk(we.GetPrevious(), layer, depth+1, visit)
+}
+
+// findInChain returns the first error of the chain accepted by match, either the error or the actual
+// error of a layer, with the fields of that layer.
+func findInChain(err error, match func(error) bool) (error, map[string]interface{}, bool) {
+	var found error
+	var fields map[string]interface{}
+	Walk(err, func(l Layer) bool {
+		if match(l.Err) {
+			found = l.Err
+		} else if _, isWrappedError := l.Err.(WrappedError); isWrappedError && match(l.Actual) {
+			found = l.Actual
+		} else {
+			return true
+		}
+		fields = l.Fields
+		return false
+	})
+	if found == nil {
+		return nil, map[string]interface{}{}, false
+	}
+	return found, fields, true
+}
+
+// unwrapCauses returns the errors wrapped by err through Unwrap() error or Unwrap() []error.
+func unwrapCauses(err error) []error {
+	switch x := err.(type) {
+	case interface{ Unwrap() []error }:
+		return x.Unwrap()
+	case interface{ Unwrap() error }:
+		if cause := x.Unwrap(); cause != nil {
+			return []error{cause}
+		}
+	}
+	return nil
+}
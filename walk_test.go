@@ -0,0 +1,103 @@
+package errors
+
+import (
+	goerr "errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalk(t *testing.T) {
+
+	t.Run("should visit every layer with its depth, fields and location", func(t *testing.T) {
+		err := getThirdWrap()
+		var layers []Layer
+		Walk(err, func(l Layer) bool {
+			layers = append(layers, l)
+			return true
+		})
+
+		assert.Len(t, layers, 3)
+		assert.Equal(t, "third wrap", layers[0].Actual.Error())
+		assert.Equal(t, 0, layers[0].Depth)
+		assert.Equal(t, "github.com/hantonelli/errors/wrappederror_helper_test.go:18", layers[0].Location)
+		assert.Equal(t, "test-string", layers[0].Fields["third-wrap-string"])
+		assert.Equal(t, "second wrap", layers[1].Actual.Error())
+		assert.Equal(t, 1, layers[1].Depth)
+		assert.Equal(t, "previous", layers[2].Actual.Error())
+		assert.Equal(t, 2, layers[2].Depth)
+		assert.Equal(t, "github.com/hantonelli/errors/wrappederror_helper_test.go:35", layers[2].Location)
+	})
+
+	t.Run("should give errors that are not wrapped the fields of their layer", func(t *testing.T) {
+		err := WithErrorAndFields(goerr.New("previous"), goerr.New("actual"), fields)
+		var layers []Layer
+		Walk(err, func(l Layer) bool {
+			layers = append(layers, l)
+			return true
+		})
+
+		assert.Len(t, layers, 2)
+		assert.Equal(t, "previous", layers[1].Err.Error())
+		assert.Equal(t, layers[1].Err, layers[1].Actual)
+		assert.Equal(t, fields, layers[1].Fields)
+		assert.Equal(t, layers[0].Location, layers[1].Location)
+		assert.Equal(t, 1, layers[1].Depth)
+	})
+
+	t.Run("should follow fmt.Errorf and errors.Join", func(t *testing.T) {
+		inner := NewWithErrorAndFields(goerr.New("inner"), fields)
+		err := fmt.Errorf("outer: %w", goerr.Join(goerr.New("other"), inner))
+		var messages []string
+		Walk(err, func(l Layer) bool {
+			messages = append(messages, l.Actual.Error())
+			return true
+		})
+
+		assert.Equal(t, []string{"outer: other\n" + inner.Error(), "other\n" + inner.Error(), "other", "inner"}, messages)
+	})
+
+	t.Run("should stop when visit returns false", func(t *testing.T) {
+		count := 0
+		Walk(getThirdWrap(), func(l Layer) bool {
+			count++
+			return count < 2
+		})
+		assert.Equal(t, 2, count)
+	})
+}
+
+func TestFind(t *testing.T) {
+	first := NewWithErrorAndFields(goerr.New("quota exceeded for tenant"), map[string]interface{}{"tenant": "a"})
+	second := WithErrorAndFields(first, goerr.New("quota exceeded for tenant"), map[string]interface{}{"tenant": "b"})
+	err := WithError(second, goerr.New("request failed"))
+
+	quotaForTenant := func(tenant string) func(Layer) bool {
+		return func(l Layer) bool {
+			return strings.Contains(l.Actual.Error(), "quota exceeded") && l.Fields["tenant"] == tenant
+		}
+	}
+
+	t.Run("should return the first matching layer", func(t *testing.T) {
+		l, ok := Find(err, quotaForTenant("a"))
+		assert.True(t, ok)
+		assert.Equal(t, first, l.Err)
+		assert.Equal(t, 2, l.Depth)
+	})
+
+	t.Run("should return false when no layer matches", func(t *testing.T) {
+		_, ok := Find(err, quotaForTenant("c"))
+		assert.False(t, ok)
+	})
+
+	t.Run("should return every matching layer", func(t *testing.T) {
+		layers := FindAll(err, func(l Layer) bool {
+			return strings.Contains(l.Actual.Error(), "quota exceeded")
+		})
+		assert.Len(t, layers, 2)
+		assert.Equal(t, second, layers[0].Err)
+		assert.Equal(t, first, layers[1].Err)
+	})
+}
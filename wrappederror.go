@@ -4,31 +4,10 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"go/build"
-	"os"
-	"runtime"
 	"sort"
 	"strings"
 )
 
-var (
-	gopath string
-	goroot string
-)
-
-func init() {
-	gopath = os.Getenv("GOPATH")
-	if gopath == "" {
-		gopath = build.Default.GOPATH
-	}
-	goroot = os.Getenv("GOROOT")
-	if goroot == "" {
-		goroot = build.Default.GOROOT
-	}
-	gopath = gopath + "/src"
-	goroot = goroot + "/src"
-}
-
 // location describes a source code location.
 type location struct {
 	file string
@@ -49,16 +28,20 @@ type WrappedError interface {
 	GetFields() map[string]interface{}
 	GetAllFields() map[string]interface{}
 	GetStacktrace() string
+	GetStackFrames() []Frame
 }
 
 // WrappedErrorImpl is a wrapper for an error chain that allow to specify errors fields.
 type WrappedErrorImpl struct {
-	actual     error
-	previous   error
-	stacktrace string
-
-	location location
-	fields   map[string]interface{}
+	actual         error
+	previous       error
+	stack          []uintptr
+	stackTruncated bool
+
+	pc         uintptr
+	fields     map[string]interface{}
+	code       Code
+	messageKey string
 }
 
 // IsWrappedError returns always true for this error type.
@@ -76,29 +59,70 @@ func (e WrappedErrorImpl) GetActual() error {
 	return e.actual
 }
 
-// GetFields returns the fields associated with the actual error.
+// GetFields returns the fields associated with the actual error, redacted with the redaction policy.
 func (e WrappedErrorImpl) GetFields() map[string]interface{} {
-	return e.fields
+	return redactFields(e.fields)
 }
 
-// Error returns stack of all the wrapped error messages and it associated fields.
-func (e *WrappedErrorImpl) Error() string {
-	if e.previous != nil {
-		if _, ok := e.previous.(WrappedError); ok {
-			return fmt.Sprintf("%s <br> %s", printActual(e), e.previous.Error())
-		}
-		return fmt.Sprintf("%s <br> Message: %s.", printActual(e), e.previous.Error())
+// GetFieldsUnredacted returns a copy of the fields associated with the actual error with their original values.
+func (e WrappedErrorImpl) GetFieldsUnredacted() map[string]interface{} {
+	return copyFields(e.fields)
+}
+
+// WithField returns a copy of the error with the field added to its layer. The error itself is not changed.
+func (e *WrappedErrorImpl) WithField(key string, value interface{}) *WrappedErrorImpl {
+	return e.WithFields(map[string]interface{}{key: value})
+}
+
+// WithFields returns a copy of the error with the fields added to its layer. The error itself is not changed.
+func (e *WrappedErrorImpl) WithFields(fields map[string]interface{}) *WrappedErrorImpl {
+	if e == nil {
+		return nil
+	}
+	withFields := *e
+	withFields.fields = make(map[string]interface{}, len(e.fields)+len(fields))
+	for k, v := range e.fields {
+		withFields.fields[k] = v
 	}
-	return printActual(e)
+	for k, v := range fields {
+		withFields.fields[k] = v
+	}
+	return &withFields
+}
+
+// Unwrap returns the previous error in the chain, so errors.Unwrap, errors.Is and errors.As can follow it.
+func (e WrappedErrorImpl) Unwrap() error {
+	return e.previous
+}
+
+// Is reports whether the actual error matches target, so errors.Is can see the actual error chain.
+func (e WrappedErrorImpl) Is(target error) bool {
+	return errors.Is(e.actual, target)
+}
+
+// As finds the first error in the actual error chain that matches target, so errors.As can see it.
+func (e WrappedErrorImpl) As(target interface{}) bool {
+	return errors.As(e.actual, target)
+}
+
+// Error returns stack of all the wrapped error messages and it associated fields, rendered with the
+// renderer set by SetRenderer.
+func (e *WrappedErrorImpl) Error() string {
+	return currentRenderer().Render(e)
 }
 
 func printActual(e *WrappedErrorImpl) string {
+	message := e.actual.Error()
+	if e.code != "" {
+		message = fmt.Sprintf("%s. Code: %s", message, e.code)
+	}
 	if e.fields != nil && 0 < len(e.fields) {
-		return fmt.Sprintf("Message: %v. Location: %v. Fields: %v.", e.actual.Error(), e.location, printFields(e.fields))
+		return fmt.Sprintf("Message: %v. Location: %v. Fields: %v.", message, e.location(), printFields(e.fields))
 	}
-	return fmt.Sprintf("Message: %v. Location: %v", e.actual.Error(), e.location)
+	return fmt.Sprintf("Message: %v. Location: %v", message, e.location())
 }
 
+// printFields prints the fields sorted by key, redacting them with the redaction policy.
 func printFields(fields map[string]interface{}) string {
 	keys := make([]string, 0, len(fields))
 	for k := range fields {
@@ -115,157 +139,134 @@ func printFields(fields map[string]interface{}) string {
 		} else {
 			b.WriteString(" ")
 		}
-		fmt.Fprintf(&b, "%s:%v", k, fields[k])
+		fmt.Fprintf(&b, "%s:%v", k, redactValue(k, fields[k]))
 	}
 	fmt.Fprint(&b, "]")
 
 	return b.String()
 }
 
-// GetAllFields returns a map of the fields for all the errors that had been wrap in the chain.
+// GetAllFields returns a map of the fields for all the errors that had been wrap in the chain, redacted
+// with the redaction policy. Fields set in several layers are merged with the merge policy, and when
+// the chain has a code, it is added as the CodeField field unless a field of the chain already uses it.
 func (e WrappedErrorImpl) GetAllFields() map[string]interface{} {
+	return redactFields(e.GetAllFieldsUnredacted())
+}
+
+// GetAllFieldsUnredacted returns the same fields as GetAllFields with their original values.
+func (e WrappedErrorImpl) GetAllFieldsUnredacted() map[string]interface{} {
+	return withCodeField(mergeFieldSources(fieldSources(&e), currentMergePolicy()), &e)
+}
+
+// GetFieldsWithProvenance returns every value set for each field in the chain, from the outermost
+// layer to the root, with the layer and location where it was set.
+func (e WrappedErrorImpl) GetFieldsWithProvenance() map[string][]FieldSource {
+	return redactFieldSources(fieldSources(&e))
+}
+
+// GetStacktrace returns the stack trace of the first error in the chain, as space separated file:line
+// frames followed by "..." when frames were dropped.
+func (e WrappedErrorImpl) GetStacktrace() string {
 	if e.previous != nil {
 		if we, ok := e.previous.(WrappedError); ok {
-			previousFields := we.GetAllFields()
-			newFields := e.fields
-			for k := range previousFields {
-				newFields[k] = previousFields[k]
-			}
-			return newFields
+			return we.GetStacktrace()
 		}
-		return e.fields
 	}
-	return e.fields
+	frames := make([]string, 0, len(e.stack)+1)
+	for _, frame := range resolveFrames(e.stack) {
+		frames = append(frames, frame.String())
+	}
+	if e.stackTruncated {
+		frames = append(frames, truncatedMarker)
+	}
+	return strings.Join(frames, " ")
 }
 
-// GetStacktrace returns the stack trace of the first error in the chain.
-func (e WrappedErrorImpl) GetStacktrace() string {
+// GetStackFrames returns the stack frames of the first error in the chain.
+func (e WrappedErrorImpl) GetStackFrames() []Frame {
 	if e.previous != nil {
 		if we, ok := e.previous.(WrappedError); ok {
-			return we.GetStacktrace()
+			return we.GetStackFrames()
 		}
 	}
-	return e.stacktrace
+	return resolveFrames(e.stack)
+}
+
+// IsStackTruncated returns true when frames of the first error in the chain were dropped because the
+// stack was deeper than the maximum depth.
+func (e WrappedErrorImpl) IsStackTruncated() bool {
+	if e.previous != nil {
+		if st, ok := e.previous.(interface{ IsStackTruncated() bool }); ok {
+			return st.IsStackTruncated()
+		}
+	}
+	return e.stackTruncated
 }
 
 // NewWithMsg returns a new WrappedErrorImpl with the provided message.
 func NewWithMsg(message string) *WrappedErrorImpl {
-	actual := errors.New(message)
-	return createWrappedError(nil, actual, nil)
+	return Msg(message).build()
 }
 
 // NewWithMsgAndFields returns a new WrappedErrorImpl with the provided message and fields.
 func NewWithMsgAndFields(message string, fields map[string]interface{}) *WrappedErrorImpl {
-	actual := errors.New(message)
-	return createWrappedError(nil, actual, fields)
+	return Msg(message).Fields(fields).build()
 }
 
 // NewWithError returns a new WrappedErrorImpl with the provided error.
 func NewWithError(actual error) *WrappedErrorImpl {
-	return createWrappedError(nil, actual, nil)
+	return From(actual).build()
 }
 
 // NewWithErrorAndFields returns a new WrappedErrorImpl with the provided error and fields.
 func NewWithErrorAndFields(actual error, fields map[string]interface{}) *WrappedErrorImpl {
-	return createWrappedError(nil, actual, fields)
+	return From(actual).Fields(fields).build()
 }
 
 // WithError takes the previous error and the actual error and, returns a new WrappedErrorImpl.
 func WithError(previous error, actual error) *WrappedErrorImpl {
-	return createWrappedError(previous, actual, nil)
+	return From(actual).Wrap(previous).build()
 }
 
 // WithErrorAndFields takes the previous error, the actual error and the fields associated with it and returns a new WrappedErrorImpl.
 func WithErrorAndFields(previous error, actual error, fields map[string]interface{}) *WrappedErrorImpl {
-	return createWrappedError(previous, actual, fields)
-}
-
-func createWrappedError(previous error, actual error, fields map[string]interface{}) *WrappedErrorImpl {
-	loc := getLocation()
-	if actual == nil {
-		return nil
-	}
-	if fields == nil {
-		fields = map[string]interface{}{}
-	}
-	var stacktrace string
-	if previous == nil {
-		stacktrace = getStacktrace()
-	}
-	return &WrappedErrorImpl{
-		actual:     actual,
-		previous:   previous,
-		fields:     fields,
-		location:   loc,
-		stacktrace: stacktrace,
-	}
-}
-
-func getLocation() location {
-	_, file, line, _ := runtime.Caller(3)
-	file = cleanFilePath(file)
-	return location{file: file, line: line}
+	return From(actual).Wrap(previous).Fields(fields).build()
 }
 
-func cleanFilePath(file string) string {
-	if strings.HasPrefix(file, gopath) {
-		file = strings.TrimPrefix(file, gopath)
-	} else {
-		if strings.HasPrefix(file, goroot) {
-			file = strings.TrimPrefix(file, goroot)
-		}
+// copyFields returns a copy of fields, so the fields of an error never share a map with its caller and
+// can be read from many goroutines.
+func copyFields(fields map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		copied[k] = v
 	}
-	return file
+	return copied
 }
 
-func getStacktrace() string {
-	n := 2
-	var b bytes.Buffer
-	first := true
-	for i := 0; i < 20; i++ {
-		_, file, line, ok := runtime.Caller(n + 1)
-		if !ok {
-			return b.String()
-		}
-		file = cleanFilePath(file)
-		if first {
-			first = false
-		} else {
-			fmt.Fprint(&b, " ")
-		}
-		fmt.Fprintf(&b, "%s:%d", file, line)
-		n++
+// location returns the location where the error was wrapped, resolving it on first use.
+func (e WrappedErrorImpl) location() location {
+	if e.pc == 0 {
+		return location{}
 	}
-	return b.String()
+	frame := symbolize(e.pc)
+	return location{file: cleanFilePath(frame.File), line: frame.Line}
 }
 
 // ContainsError takes an error to look for and the error that needs to analyse. It compares the
-// errors by comparing the string returned by Error().
+// errors by identity, see MatchIdentity, so it is meant to look for sentinel errors.
 func ContainsError(lookFor, err error) (error, map[string]interface{}, bool) {
+	return ContainsErrorBy(lookFor, err, MatchIdentity)
+}
+
+// ContainsErrorBy takes an error to look for, the error that needs to analyse and the matcher used to
+// compare them, such as MatchMessage to compare the string returned by Error().
+func ContainsErrorBy(lookFor, err error, match Matcher) (error, map[string]interface{}, bool) {
 	if lookFor == nil || err == nil {
 		return nil, map[string]interface{}{}, false
 	}
-	if err.Error() == lookFor.Error() {
-		return err, map[string]interface{}{}, true
-	}
-	we, isWrappedError := err.(WrappedError)
-	if !isWrappedError {
-		return nil, map[string]interface{}{}, false
-	}
-
-	if we.GetActual().Error() == lookFor.Error() {
-		return we.GetActual(), we.GetFields(), true
-	}
-	if we.GetPrevious() != nil {
-		if we.GetPrevious().Error() == lookFor.Error() {
-			return we.GetPrevious(), we.GetFields(), true
-		}
-		prev, isPreviousWrappedError := we.GetPrevious().(WrappedError)
-		if isPreviousWrappedError {
-			return ContainsError(lookFor, prev)
-		}
-	}
-	return nil, map[string]interface{}{}, false
+	return findInChain(err, func(e error) bool {
+		return match(lookFor, e)
+	})
 }
 
 // ContainsErrorPrefix takes a prefix msg to look and an error chain and returns the error if it is found.
@@ -273,25 +274,7 @@ func ContainsErrorPrefix(prefixMsg string, err error) (error, map[string]interfa
 	if prefixMsg == "" || err == nil {
 		return nil, map[string]interface{}{}, false
 	}
-	if err.Error() != "" && strings.HasPrefix(err.Error(), prefixMsg) {
-		return err, map[string]interface{}{}, true
-	}
-	we, isWrappedError := err.(WrappedError)
-	if !isWrappedError {
-		return nil, map[string]interface{}{}, false
-	}
-
-	if we.GetActual().Error() != "" && strings.HasPrefix(we.GetActual().Error(), prefixMsg) {
-		return we.GetActual(), we.GetFields(), true
-	}
-	if we.GetPrevious() != nil {
-		if we.GetPrevious().Error() != "" && strings.HasPrefix(we.GetPrevious().Error(), prefixMsg) {
-			return we.GetPrevious(), we.GetFields(), true
-		}
-		prev, isPreviousWrappedError := we.GetPrevious().(WrappedError)
-		if isPreviousWrappedError {
-			return ContainsErrorPrefix(prefixMsg, prev)
-		}
-	}
-	return nil, map[string]interface{}{}, false
+	return findInChain(err, func(e error) bool {
+		return e.Error() != "" && strings.HasPrefix(e.Error(), prefixMsg)
+	})
 }
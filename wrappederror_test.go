@@ -21,7 +21,7 @@ func TestNewWithMessage(t *testing.T) {
 		message := fmt.Sprintf("error vii, %v", 22)
 
 		var err error = NewWithMsgAndFields(message, nil)
-		assert.Equal(t, err.Error(), "Message: error vii, 22. Location: /github.com/hantonelli/errors/wrappederror_test.go:23")
+		assert.Equal(t, err.Error(), "Message: error vii, 22. Location: github.com/hantonelli/errors/wrappederror_test.go:23")
 		assert.NotNil(t, err)
 		we, ok := err.(WrappedError)
 		if !ok {
@@ -44,7 +44,7 @@ func TestNewWithError(t *testing.T) {
 	t.Run("should handle error and no fields", func(t *testing.T) {
 		errActual := fmt.Errorf("error vii, %v", 22)
 		var err error = NewWithErrorAndFields(errActual, nil)
-		assert.Equal(t, err.Error(), "Message: error vii, 22. Location: /github.com/hantonelli/errors/wrappederror_test.go:46")
+		assert.Equal(t, err.Error(), "Message: error vii, 22. Location: github.com/hantonelli/errors/wrappederror_test.go:46")
 		assert.NotNil(t, err)
 		we, ok := err.(WrappedError)
 		if !ok {
@@ -59,7 +59,7 @@ func TestNewWithError(t *testing.T) {
 	t.Run("should handle error and fields", func(t *testing.T) {
 		errActual := fmt.Errorf("error vii, %v", 22)
 		var err error = NewWithErrorAndFields(errActual, fields)
-		assert.Equal(t, err.Error(), "Message: error vii, 22. Location: /github.com/hantonelli/errors/wrappederror_test.go:61. Fields: map[key:value key2:12].")
+		assert.Equal(t, err.Error(), "Message: error vii, 22. Location: github.com/hantonelli/errors/wrappederror_test.go:61. Fields: map[key:value key2:12].")
 		assert.NotNil(t, err)
 		we, ok := err.(WrappedError)
 		if !ok {
@@ -75,7 +75,7 @@ func TestNewWithError(t *testing.T) {
 		errActual := fmt.Errorf("error vii, %v", 22)
 		var err error = WithErrorAndFields(errPrevious, errActual, fields)
 		assert.NotNil(t, err)
-		assert.Equal(t, err.Error(), "Message: error vii, 22. Location: /github.com/hantonelli/errors/wrappederror_test.go:76. Fields: map[key:value key2:12]. <br> Message: previous.")
+		assert.Equal(t, err.Error(), "Message: error vii, 22. Location: github.com/hantonelli/errors/wrappederror_test.go:76. Fields: map[key:value key2:12]. <br> Message: previous.")
 		assert.NotNil(t, err)
 		we, ok := err.(WrappedError)
 		if !ok {
@@ -91,15 +91,15 @@ func TestNewWithError(t *testing.T) {
 		assert.NotNil(t, err)
 		expectedMsg := `
 			Message: third wrap.
-			 Location: /github.com/hantonelli/errors/wrappederror_helper_test.go:18.
+			 Location: github.com/hantonelli/errors/wrappederror_helper_test.go:18.
 			 Fields: map[third-wrap-number:123 third-wrap-string:test-string]. <br>
 
 			 Message: second wrap.
-			 Location: /github.com/hantonelli/errors/wrappederror_helper_test.go:27.
+			 Location: github.com/hantonelli/errors/wrappederror_helper_test.go:27.
 			 Fields: map[second-wrap-number:123 second-wrap-string:test-string]. <br>
 
 			 Message: previous.
-			 Location: /github.com/hantonelli/errors/wrappederror_helper_test.go:35.
+			 Location: github.com/hantonelli/errors/wrappederror_helper_test.go:35.
 			 Fields: map[first-wrap-number:123 first-wrap-string:test-string].
 		`
 		expectedMsg = cleanSpaces(expectedMsg)
@@ -124,10 +124,10 @@ func TestNewWithError(t *testing.T) {
 
 	t.Run("should return stacktrace", func(t *testing.T) {
 		expectedStacktrace := `
-			/github.com/hantonelli/errors/wrappederror_helper_test.go:35
-			 /github.com/hantonelli/errors/wrappederror_helper_test.go:25
-			 /github.com/hantonelli/errors/wrappederror_helper_test.go:16
-			 /github.com/hantonelli/errors/wrappederror_test.go:134
+			github.com/hantonelli/errors/wrappederror_helper_test.go:35
+			 github.com/hantonelli/errors/wrappederror_helper_test.go:25
+			 github.com/hantonelli/errors/wrappederror_helper_test.go:16
+			 github.com/hantonelli/errors/wrappederror_test.go:134
 		`
 		expectedStacktrace = cleanSpaces(expectedStacktrace)
 
@@ -159,7 +159,7 @@ func TestContainsError(t *testing.T) {
 	})
 
 	t.Run("return error if we look for the same error that is provided", func(t *testing.T) {
-		err := goerr.New("expected error")
+		err := errLookFor
 		actualErr, actualFields, ok := ContainsError(errLookFor, err)
 		assert.True(t, ok)
 		assert.Equal(t, errLookFor, actualErr)
@@ -167,7 +167,7 @@ func TestContainsError(t *testing.T) {
 	})
 
 	t.Run("return error if it is wrap", func(t *testing.T) {
-		err := goerr.New("expected error")
+		err := errLookFor
 		wrappedError1 := NewWithErrorAndFields(err, fields)
 
 		actualErr, actualFields, ok := ContainsError(errLookFor, wrappedError1)
@@ -179,7 +179,7 @@ func TestContainsError(t *testing.T) {
 	})
 
 	t.Run("return error if it is wrap three times and is in the end", func(t *testing.T) {
-		err := goerr.New("expected error")
+		err := errLookFor
 		wrappedError1 := NewWithErrorAndFields(err, fields)
 		err2 := goerr.New("err 2")
 		wrappedError2 := WithError(wrappedError1, err2)
@@ -197,7 +197,7 @@ func TestContainsError(t *testing.T) {
 	t.Run("return error if it is wrap three times and is in the middle", func(t *testing.T) {
 		err := goerr.New("err 1")
 		wrappedError1 := NewWithError(err)
-		err2 := goerr.New("expected error")
+		err2 := errLookFor
 		wrappedError2 := WithErrorAndFields(wrappedError1, err2, fields)
 		err3 := goerr.New("err 3")
 		wrappedError3 := WithError(wrappedError2, err3)
@@ -215,7 +215,7 @@ func TestContainsError(t *testing.T) {
 		wrappedError1 := NewWithError(err)
 		err2 := goerr.New("err 3")
 		wrappedError2 := WithError(wrappedError1, err2)
-		err3 := goerr.New("expected error")
+		err3 := errLookFor
 		wrappedError3 := WithErrorAndFields(wrappedError2, err3, fields)
 
 		actualErr, actualFields, ok := ContainsError(errLookFor, wrappedError3)
@@ -313,3 +313,72 @@ func TestContainsErrorPrefix(t *testing.T) {
 		}
 	})
 }
+
+func TestStandardUnwrapping(t *testing.T) {
+	errPrevious := goerr.New("previous")
+	errActual := goerr.New("actual")
+
+	t.Run("should unwrap to the previous error", func(t *testing.T) {
+		err := WithError(errPrevious, errActual)
+		assert.Equal(t, errPrevious, goerr.Unwrap(err))
+	})
+
+	t.Run("should match the actual and previous errors with errors.Is", func(t *testing.T) {
+		var err error = WithError(WithError(errPrevious, errActual), goerr.New("outer"))
+		assert.True(t, goerr.Is(err, errActual))
+		assert.True(t, goerr.Is(err, errPrevious))
+		assert.False(t, goerr.Is(err, goerr.New("actual")))
+	})
+
+	t.Run("should match through fmt.Errorf and errors.Join", func(t *testing.T) {
+		inner := WithError(fmt.Errorf("context: %w", errPrevious), errActual)
+		var err error = fmt.Errorf("outer: %w", goerr.Join(goerr.New("other"), inner))
+		assert.True(t, goerr.Is(err, errActual))
+		assert.True(t, goerr.Is(err, errPrevious))
+	})
+
+	t.Run("should find the actual error type with errors.As", func(t *testing.T) {
+		var err error = WithError(errPrevious, genericError{})
+		var ge GenericError
+		assert.True(t, goerr.As(err, &ge))
+		assert.Equal(t, "GenericError", ge.Error())
+	})
+
+	t.Run("should find the wrapped error with errors.As", func(t *testing.T) {
+		var err error = fmt.Errorf("outer: %w", NewWithErrorAndFields(errActual, fields))
+		var we *WrappedErrorImpl
+		assert.True(t, goerr.As(err, &we))
+		assert.Equal(t, errActual, we.GetActual())
+	})
+}
+
+func TestContainsErrorStandardChains(t *testing.T) {
+	errLookFor := goerr.New("expected error")
+
+	t.Run("return error wrapped with fmt.Errorf under a wrapped error", func(t *testing.T) {
+		err := WithErrorAndFields(fmt.Errorf("context: %w", errLookFor), goerr.New("outer"), fields)
+
+		actualErr, actualFields, ok := ContainsError(errLookFor, err)
+		assert.True(t, ok)
+		assert.Equal(t, errLookFor, actualErr)
+		assert.Equal(t, fields, actualFields)
+	})
+
+	t.Run("return error from a wrapped error under errors.Join", func(t *testing.T) {
+		wrapped := NewWithErrorAndFields(errLookFor, fields)
+		err := fmt.Errorf("outer: %w", goerr.Join(goerr.New("other"), wrapped))
+
+		actualErr, actualFields, ok := ContainsError(errLookFor, err)
+		assert.True(t, ok)
+		assert.Equal(t, errLookFor, actualErr)
+		assert.Equal(t, fields, actualFields)
+	})
+
+	t.Run("return error by prefix through fmt.Errorf", func(t *testing.T) {
+		err := WithError(fmt.Errorf("context: %w", goerr.New("expected error to occur")), goerr.New("outer"))
+
+		actualErr, _, ok := ContainsErrorPrefix("expected error", err)
+		assert.True(t, ok)
+		assert.Equal(t, "expected error to occur", actualErr.Error())
+	})
+}